@@ -0,0 +1,122 @@
+package main
+
+// ahoCorasick é um autômato de Aho-Corasick sobre bytes: encontra todas as
+// ocorrências de um conjunto de strings em uma única passada pelo texto,
+// com custo que não cresce com a quantidade de padrões.
+type ahoCorasick struct {
+	next []map[byte]int
+	fail []int
+	// out lista, para cada estado, os padrões que terminam nele (incluindo os
+	// herdados pelos links de falha).
+	out  [][]int
+	lens []int
+	// fold compara letras ASCII sem diferenciar maiúsculas, preservando as
+	// posições em bytes do texto original.
+	fold bool
+}
+
+func foldByte(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+func newAhoCorasick(patterns []string, fold bool) *ahoCorasick {
+	ac := &ahoCorasick{
+		next: []map[byte]int{{}},
+		fail: []int{0},
+		out:  [][]int{nil},
+		lens: make([]int, len(patterns)),
+		fold: fold,
+	}
+
+	// Monta a trie com todos os padrões.
+	for i, p := range patterns {
+		ac.lens[i] = len(p)
+		if p == "" {
+			continue
+		}
+		state := 0
+		for j := 0; j < len(p); j++ {
+			b := p[j]
+			if fold {
+				b = foldByte(b)
+			}
+			n, ok := ac.next[state][b]
+			if !ok {
+				n = len(ac.next)
+				ac.next = append(ac.next, map[byte]int{})
+				ac.fail = append(ac.fail, 0)
+				ac.out = append(ac.out, nil)
+				ac.next[state][b] = n
+			}
+			state = n
+		}
+		ac.out[state] = append(ac.out[state], i)
+	}
+
+	// Calcula os links de falha em largura, a partir da raiz.
+	queue := make([]int, 0, len(ac.next))
+	for _, n := range ac.next[0] {
+		queue = append(queue, n)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for b, n := range ac.next[state] {
+			queue = append(queue, n)
+			f := ac.fail[state]
+			for {
+				if target, ok := ac.next[f][b]; ok {
+					ac.fail[n] = target
+					break
+				}
+				if f == 0 {
+					ac.fail[n] = 0
+					break
+				}
+				f = ac.fail[f]
+			}
+			ac.out[n] = append(ac.out[n], ac.out[ac.fail[n]]...)
+		}
+	}
+	return ac
+}
+
+// findAll chama fn para cada ocorrência de cada padrão, inclusive sobrepostas,
+// até que fn devolva false.
+func (ac *ahoCorasick) findAll(s string, fn func(pattern, start, end int) bool) {
+	state := 0
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if ac.fold {
+			b = foldByte(b)
+		}
+		for {
+			if n, ok := ac.next[state][b]; ok {
+				state = n
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = ac.fail[state]
+		}
+		for _, p := range ac.out[state] {
+			if !fn(p, i+1-ac.lens[p], i+1) {
+				return
+			}
+		}
+	}
+}
+
+// matchAny indica se algum dos padrões ocorre no texto.
+func (ac *ahoCorasick) matchAny(s string) bool {
+	found := false
+	ac.findAll(s, func(int, int, int) bool {
+		found = true
+		return false
+	})
+	return found
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// tokenRefreshMargin é a antecedência com que o token de instalação é
+// renovado antes de expirar (ele vale uma hora).
+const tokenRefreshMargin = 5 * time.Minute
+
+// appAuth autentica como GitHub App: assina um JWT com a chave privada da App
+// e o troca por tokens de instalação, renovados conforme expiram.
+type appAuth struct {
+	id           string
+	key          *rsa.PrivateKey
+	installation string
+	client       *githubClient
+}
+
+// newAppAuth lê a chave privada (PEM em PKCS#1 ou PKCS#8) da App.
+func newAppAuth(id, keyPath, installation string, client *githubClient) (*appAuth, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("a chave privada não está em formato PEM")
+	}
+	var key *rsa.PrivateKey
+	if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		parsed, err8 := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err8 != nil {
+			return nil, fmt.Errorf("chave privada inválida: %w", err)
+		}
+		var ok bool
+		if key, ok = parsed.(*rsa.PrivateKey); !ok {
+			return nil, errors.New("a chave privada da GitHub App deve ser RSA")
+		}
+	}
+	return &appAuth{id: id, key: key, installation: installation, client: client}, nil
+}
+
+// jwt gera o JWT da App (RS256), válido por 9 minutos. O iat recua um minuto
+// para tolerar diferença de relógio com o GitHub.
+func (a *appAuth) jwt() (string, error) {
+	now := time.Now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.id,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// call faz uma requisição autenticada com o JWT da App e decodifica a resposta.
+func (a *appAuth) call(method, path string, want int, v any) error {
+	jwt, err := a.jwt()
+	if err != nil {
+		return fmt.Errorf("assinar JWT: %w", err)
+	}
+	req, err := http.NewRequest(method, a.client.api(path), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	resp, err := a.client.do(req)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != want {
+		return fmt.Errorf("API retornou status %d em %s: %s", resp.StatusCode, path, string(body))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// token emite um token de instalação. Sem -app-installation, usa a única
+// instalação da App.
+func (a *appAuth) token() (string, time.Time, error) {
+	if a.installation == "" {
+		var installations []struct {
+			ID      int64 `json:"id"`
+			Account struct {
+				Login string `json:"login"`
+			} `json:"account"`
+		}
+		if err := a.call("GET", "/app/installations", http.StatusOK, &installations); err != nil {
+			return "", time.Time{}, err
+		}
+		if len(installations) != 1 {
+			return "", time.Time{}, fmt.Errorf("a App tem %d instalações; escolha uma com -app-installation", len(installations))
+		}
+		a.installation = strconv.FormatInt(installations[0].ID, 10)
+	}
+	var access struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := a.call("POST", "/app/installations/"+a.installation+"/access_tokens", http.StatusCreated, &access); err != nil {
+		return "", time.Time{}, err
+	}
+	return access.Token, access.ExpiresAt, nil
+}
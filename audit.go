@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditEntry é uma linha do log de auditoria: uma requisição feita à API.
+type auditEntry struct {
+	Time       time.Time         `json:"time"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     int               `json:"status,omitempty"`
+	DurationMS int64             `json:"duration_ms"`
+	RateLimit  map[string]string `json:"rate_limit,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// rateLimitHeaders são os cabeçalhos de limite copiados para o log.
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Used",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Resource",
+	"Retry-After",
+}
+
+// auditLog grava cada requisição de saída em JSONL (-audit-log). O arquivo é
+// aberto em modo append para acumular o histórico entre execuções.
+type auditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(file)
+	enc.SetEscapeHTML(false)
+	return &auditLog{file: file, enc: enc}, nil
+}
+
+// record registra a requisição com o resultado (resposta ou erro) e a duração.
+func (a *auditLog) record(req *http.Request, resp *http.Response, err error, start time.Time) error {
+	entry := auditEntry{
+		Time:       start.UTC(),
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		for _, h := range rateLimitHeaders {
+			if v := resp.Header.Get(h); v != "" {
+				if entry.RateLimit == nil {
+					entry.RateLimit = make(map[string]string)
+				}
+				entry.RateLimit[h] = v
+			}
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(entry)
+}
+
+func (a *auditLog) Close() error {
+	return a.file.Close()
+}
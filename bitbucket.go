@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Raízes da API e do site do Bitbucket Cloud.
+const (
+	bitbucketAPIURL = "https://api.bitbucket.org/2.0"
+	bitbucketWebURL = "https://bitbucket.org"
+)
+
+// bitbucketSearcher usa a busca de código de um workspace do Bitbucket Cloud
+// (-provider bitbucket, com o workspace em -org). O token vai como Bearer
+// (access token) ou, com usuário, como app password na autenticação básica.
+type bitbucketSearcher struct {
+	workspace string
+	username  string
+	token     string
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+}
+
+// bitbucketSearchResponse é a parte usada da resposta da busca de código.
+type bitbucketSearchResponse struct {
+	Size   int    `json:"size"`
+	Next   string `json:"next"`
+	Values []struct {
+		ContentMatches []struct {
+			Lines []struct {
+				Segments []struct {
+					Text string `json:"text"`
+				} `json:"segments"`
+			} `json:"lines"`
+		} `json:"content_matches"`
+		File struct {
+			Path  string `json:"path"`
+			Links struct {
+				Self struct {
+					Href string `json:"href"`
+				} `json:"self"`
+			} `json:"links"`
+		} `json:"file"`
+	} `json:"values"`
+}
+
+// bitbucketFile extrai o repositório e o commit do link do arquivo na API
+// (.../repositories/workspace/repo/src/commit/caminho).
+func bitbucketFile(href string) (repo, commit string) {
+	_, rest, ok := strings.Cut(href, "/repositories/")
+	if !ok {
+		return "", ""
+	}
+	repo, rest, _ = strings.Cut(rest, "/src/")
+	commit, _, _ = strings.Cut(rest, "/")
+	return repo, commit
+}
+
+func (b *bitbucketSearcher) searchCode(query string, page, perPage int) (*CodeSearchResult, error) {
+	apiURL := fmt.Sprintf("%s/workspaces/%s/search/code?search_query=%s&page=%d&pagelen=%d", bitbucketAPIURL, url.PathEscape(b.workspace), url.QueryEscape(query), page, perPage)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	if b.username != "" {
+		req.SetBasicAuth(b.username, b.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	var resp bitbucketSearchResponse
+	if _, err := b.client.fetchJSON(req, &resp); err != nil {
+		return nil, err
+	}
+
+	// Converte para o formato da API do GitHub, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{TotalCount: resp.Size}
+	for _, v := range resp.Values {
+		repo, commit := bitbucketFile(v.File.Links.Self.Href)
+		item := CodeSearchItem{
+			Path:    v.File.Path,
+			HTMLURL: fmt.Sprintf("%s/%s/src/%s/%s", bitbucketWebURL, repo, commit, v.File.Path),
+		}
+		item.Repository.FullName = repo
+		for _, m := range v.ContentMatches {
+			lines := make([]string, len(m.Lines))
+			for i, l := range m.Lines {
+				var text strings.Builder
+				for _, s := range l.Segments {
+					text.WriteString(s.Text)
+				}
+				lines[i] = text.String()
+			}
+			item.TextMatches = append(item.TextMatches, TextMatch{Fragment: strings.Join(lines, "\n")})
+		}
+		result.Items = append(result.Items, item)
+	}
+	result.last = resp.Next == ""
+
+	if err := b.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// codeSearcher devolve uma página de resultados da busca de código. A API do
+// GitHub e a reprodução de respostas gravadas (-replay) implementam a interface.
+type codeSearcher interface {
+	searchCode(query string, page, perPage int) (*CodeSearchResult, error)
+}
+
+// githubAPIURL é a raiz da API REST do GitHub; no GitHub Enterprise Server
+// (-github-url) ela fica em /api/v3 no próprio servidor.
+const githubAPIURL = "https://api.github.com"
+
+// enterpriseAPIPath é o caminho da API REST no GitHub Enterprise Server.
+const enterpriseAPIPath = "/api/v3"
+
+// githubClient faz as buscas na API de código do GitHub.
+type githubClient struct {
+	tokens *tokenPool
+	// apiURL é a raiz da API de um GitHub Enterprise Server (-github-url);
+	// vazia, usa a do github.com.
+	apiURL string
+	// recorder, quando definido, guarda cada resposta bruta (-record).
+	recorder *recorder
+	// audit, quando definido, registra cada requisição (-audit-log).
+	audit *auditLog
+	// retries e retryWait controlam as novas tentativas após falhas
+	// transitórias de rede ou do servidor (-retries, -retry-wait).
+	retries   int
+	retryWait time.Duration
+	// quiet esconde a contagem regressiva das pausas por limite (-s).
+	quiet bool
+}
+
+// httpClient tem um tempo limite para uma conexão travada virar erro (e nova
+// tentativa) em vez de parar a busca.
+var httpClient = &http.Client{Timeout: time.Minute}
+
+// parseGitHubURL valida o endereço de -github-url. Só com o servidor (ex:
+// https://ghe.acme.com), acrescenta o caminho da API, /api/v3.
+func parseGitHubURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(raw, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("endereço inválido: %q", raw)
+	}
+	if u.Path == "" && u.Host != "api.github.com" {
+		u.Path = enterpriseAPIPath
+	}
+	return u.String(), nil
+}
+
+// api devolve o endereço da rota na API do GitHub ou do Enterprise Server.
+func (c *githubClient) api(path string) string {
+	if c.apiURL != "" {
+		return c.apiURL + path
+	}
+	return githubAPIURL + path
+}
+
+// web devolve a raiz do site, onde ficam o login e as páginas dos arquivos:
+// no Enterprise Server, o próprio servidor, sem /api/v3.
+func (c *githubClient) web() string {
+	if c.apiURL == "" || c.apiURL == githubAPIURL {
+		return githubWebURL
+	}
+	return strings.TrimSuffix(c.apiURL, enterpriseAPIPath)
+}
+
+// apiHost devolve o servidor de uma raiz da API (api.github.com, ghe.acme.com).
+func apiHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// host devolve o nome do servidor do GitHub, como o gh CLI o identifica.
+func (c *githubClient) host() string {
+	u, err := url.Parse(c.web())
+	if err != nil {
+		return "github.com"
+	}
+	return u.Host
+}
+
+// do envia a requisição e a registra no log de auditoria.
+func (c *githubClient) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if c.audit != nil {
+		if aerr := c.audit.record(req, resp, err, start); aerr != nil {
+			return nil, fmt.Errorf("gravar log de auditoria: %w", aerr)
+		}
+	}
+	return resp, err
+}
+
+func (c *githubClient) searchCode(query string, page, perPage int) (*CodeSearchResult, error) {
+	// A query deve ser simples para a API.
+	apiURL := fmt.Sprintf("%s?q=%s&page=%d&per_page=%d", c.api("/search/code"), url.QueryEscape(query), page, perPage)
+	status, _, body, err := c.get(apiURL, "application/vnd.github.v3.text-match+json")
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("API retornou status %d: %s", status, string(body))
+	}
+
+	if c.recorder != nil {
+		if err := c.recorder.save(query, page, body); err != nil {
+			return nil, fmt.Errorf("gravar resposta (-record): %w", err)
+		}
+	}
+
+	var result CodeSearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decodificar JSON: %w", err)
+	}
+	result.last = lastAPIPage(&result, page, perPage)
+	return &result, nil
+}
+
+// lastAPIPage indica se a página é a última da busca. A API do GitHub retorna
+// no máximo 1000 resultados (10 páginas com 100 itens cada).
+func lastAPIPage(result *CodeSearchResult, page, perPage int) bool {
+	return page*perPage >= result.TotalCount || page >= 10
+}
+
+// get faz um GET autenticado e devolve o status, os cabeçalhos e o corpo da
+// resposta, com as novas tentativas de retry.
+func (c *githubClient) get(apiURL, accept string) (int, http.Header, []byte, error) {
+	return c.retry(func() (int, http.Header, []byte, error) {
+		return c.send(apiURL, accept)
+	})
+}
+
+// retry repete a requisição feita por attempt conforme a resposta. Com o
+// limite esgotado, troca de token ou espera o reset; no limite secundário
+// (abuso), espera e tenta de novo; falhas transitórias (rede, 5xx) são
+// repetidas até c.retries vezes.
+func (c *githubClient) retry(attempt func() (int, http.Header, []byte, error)) (int, http.Header, []byte, error) {
+	secondary, transient, primary := 0, 0, 0
+	for {
+		status, header, body, err := attempt()
+		switch {
+		case err == nil && isPrimaryLimit(status, header) && primary < len(c.tokens.tokens):
+			// Com outro token disponível, tenta de novo na hora; senão,
+			// espera o reset e continua da mesma página.
+			if wait := c.tokens.exhaustedFor(); wait > 0 {
+				pause(wait, c.quiet)
+				primary = 0
+			} else {
+				primary++
+			}
+		case err == nil && isSecondaryLimit(status, body) && secondary < maxSecondaryRetries:
+			wait := retryAfter(header, secondaryBackoff<<secondary)
+			secondary++
+			log.Printf("Limite secundário da API atingido; nova tentativa em %s", wait)
+			time.Sleep(wait)
+		case isTransient(status, err) && transient < c.retries:
+			wait := jitter(c.retryWait << transient)
+			transient++
+			log.Printf("Falha transitória (%s); tentativa %d de %d em %s", describeFailure(status, err), transient, c.retries, wait.Round(time.Millisecond))
+			time.Sleep(wait)
+		default:
+			return status, header, body, err
+		}
+	}
+}
+
+// send faz uma única tentativa do GET, com o próximo token do pool.
+func (c *githubClient) send(apiURL, accept string) (int, http.Header, []byte, error) {
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	token := c.tokens.pick()
+	if err := token.fresh(); err != nil {
+		return 0, nil, nil, fmt.Errorf("renovar token da GitHub App: %w", err)
+	}
+	if token.value != "" {
+		req.Header.Set("Authorization", "token "+token.value)
+	}
+
+	resp, err := c.do(req)
+	c.tokens.update(token, resp)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("requisição: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("ler resposta: %w", err)
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+// fileContent baixa um arquivo do branch padrão do repositório; devolve nil
+// se ele não existir.
+func (c *githubClient) fileContent(repo, path string) ([]byte, error) {
+	apiURL := c.api(fmt.Sprintf("/repos/%s/contents/%s", repo, path))
+	status, _, body, err := c.get(apiURL, "application/vnd.github.raw")
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("API retornou status %d ao ler %s/%s: %s", status, repo, path, string(body))
+}
+
+// lastCommitter devolve quem fez o último commit no arquivo: o login no GitHub
+// (com @) ou, se a conta não estiver vinculada, o e-mail do autor.
+func (c *githubClient) lastCommitter(repo, path string) (string, error) {
+	apiURL := c.api(fmt.Sprintf("/repos/%s/commits?path=%s&per_page=1", repo, url.QueryEscape(path)))
+	status, _, body, err := c.get(apiURL, "application/vnd.github+json")
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("API retornou status %d ao ler commits de %s/%s: %s", status, repo, path, string(body))
+	}
+	var commits []struct {
+		Author *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		Commit struct {
+			Author struct {
+				Email string `json:"email"`
+			} `json:"author"`
+		} `json:"commit"`
+	}
+	if err := json.Unmarshal(body, &commits); err != nil {
+		return "", fmt.Errorf("decodificar JSON: %w", err)
+	}
+	if len(commits) == 0 {
+		return "", nil
+	}
+	if commits[0].Author != nil && commits[0].Author.Login != "" {
+		return "@" + commits[0].Author.Login, nil
+	}
+	return commits[0].Commit.Author.Email, nil
+}
+
+// pace espera antes da próxima página: até o reset, se todos os tokens
+// esgotaram o limite, ou o intervalo que distribui o limite restante.
+func (c *githubClient) pace() {
+	if wait := c.tokens.exhaustedFor(); wait > 0 {
+		pause(wait, c.quiet)
+		return
+	}
+	time.Sleep(c.tokens.throttle())
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRetryPrimaryLimit confere que, com o limite da busca esgotado, o retry
+// troca de token e, sem outro disponível, espera o reset informado nos
+// cabeçalhos reais do github.com em vez de desistir.
+func TestRetryPrimaryLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   []string
+		requests int
+		minWait  time.Duration
+	}{
+		{"outro token disponível", []string{"t1", "t2"}, 2, 0},
+		{"espera o reset", []string{"t1"}, 2, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset := time.Now().Add(time.Second)
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				exhausted := r.Header.Get("Authorization") == "token t1" && time.Now().Before(reset)
+				w.Header().Set("X-RateLimit-Limit", "10")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+				w.Header().Set("X-RateLimit-Resource", "code_search")
+				if exhausted {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+					return
+				}
+				w.Header().Set("X-RateLimit-Remaining", "9")
+				w.Write([]byte(`{"total_count":0,"items":[]}`))
+			}))
+			defer server.Close()
+
+			c := &githubClient{apiURL: server.URL, tokens: newTokenPool(tt.tokens), quiet: true}
+			start := time.Now()
+			status, _, _, err := c.get(c.api("/search/code?q=x"), "application/json")
+			if err != nil || status != http.StatusOK {
+				t.Fatalf("get = %d, %v; quer 200", status, err)
+			}
+			if requests != tt.requests {
+				t.Errorf("%d requisições; quer %d", requests, tt.requests)
+			}
+			if waited := time.Since(start); waited < tt.minWait {
+				t.Errorf("esperou %s; quer ao menos %s", waited, tt.minWait)
+			}
+		})
+	}
+}
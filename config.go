@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// clientConfig reúne as configurações de um cliente ou engajamento (-client).
+// Cada cliente tem escopo, credencial e diretório de saída próprios, para que
+// os dados de um não se misturem com os de outro.
+type clientConfig struct {
+	// Scope traz entradas de escopo (host ou *.curinga); ScopeFile, um arquivo
+	// no formato de -scope.
+	Scope     []string `json:"scope"`
+	ScopeFile string   `json:"scope_file"`
+	// Token é o token do GitHub do cliente; TokenEnv, a variável de ambiente
+	// que o contém. Sem nenhum dos dois, vale GITHUB_KEY.
+	Token    string `json:"token"`
+	TokenEnv string `json:"token_env"`
+	// Org restringe as buscas à organização do cliente, como -org.
+	Org string `json:"org"`
+	// OutputDir recebe os arquivos com caminho relativo (-o, -tee, -record,
+	// -audit-log, -nuclei-templates).
+	OutputDir string `json:"output_dir"`
+}
+
+// config é o arquivo de configuração do gfinder.
+type config struct {
+	Clients map[string]*clientConfig `json:"clients"`
+}
+
+// defaultConfigPath devolve o caminho padrão da configuração
+// (~/.config/gfinder/config.json no Linux).
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gfinder.json"
+	}
+	return filepath.Join(dir, "gfinder", "config.json")
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// client devolve a configuração de um cliente pelo nome.
+func (c *config) client(name string) (*clientConfig, error) {
+	if cc := c.Clients[name]; cc != nil {
+		return cc, nil
+	}
+	names := make([]string, 0, len(c.Clients))
+	for n := range c.Clients {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("cliente %q não encontrado (disponíveis: %s)", name, strings.Join(names, ", "))
+}
+
+// token devolve a credencial do cliente, ou "" se ele não definir uma.
+func (c *clientConfig) token() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	if c.TokenEnv != "" {
+		return os.Getenv(c.TokenEnv)
+	}
+	return ""
+}
+
+// outputDir devolve o diretório de saída com ~ expandido, criando-o.
+func (c *clientConfig) outputDir() (string, error) {
+	dir := c.OutputDir
+	if dir == "" {
+		return "", nil
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, rest)
+	}
+	return dir, os.MkdirAll(dir, 0o700)
+}
+
+// inDir coloca um caminho relativo dentro de dir; absolutos ficam como estão.
+func inDir(dir, path string) string {
+	if dir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// entropyRegex encontra sequências no alfabeto base64 (padrão e URL-safe),
+// que incluem as hexadecimais, com o padding opcional.
+var entropyRegex = regexp.MustCompile(`[A-Za-z0-9+/_\-]+={0,2}`)
+
+// entropyFields são os campos que o modo entropy acrescenta a cada resultado.
+var entropyFields = []string{"entropy"}
+
+// shannonEntropy calcula a entropia de Shannon da string, em bits por caractere.
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(s))
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// findHighEntropy extrai strings base64 e hexadecimais com entropia acima do
+// limite de -entropy-threshold e pelo menos -entropy-min-len caracteres, para
+// segredos que nenhuma regex conhece. O limite vale para o base64 (até 6
+// bits por caractere); o hexadecimal, que chega só a 4, usa o limite na mesma
+// proporção (4.5 → 3.0). Sequências sem letras ou sem dígitos, como palavras
+// e caminhos, são ignoradas.
+func findHighEntropy(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, loc := range entropyRegex.FindAllStringIndex(fragment, -1) {
+		value := fragment[loc[0]:loc[1]]
+		if len(value) < e.entropyMinLen || !strings.ContainsAny(value, "0123456789") || strings.IndexFunc(value, isLetter) < 0 {
+			continue
+		}
+		kind, threshold := "base64", e.entropyThreshold
+		if isHexString(value) {
+			kind, threshold = "hex", e.entropyThreshold*4/6
+		}
+		h := shannonEntropy(strings.TrimRight(value, "="))
+		if h < threshold {
+			continue
+		}
+		found = append(found, extraction{
+			Rule:     "high-entropy-string",
+			Value:    value,
+			Start:    loc[0],
+			End:      loc[1],
+			Severity: "medium",
+			Tags:     []string{kind},
+			Groups:   map[string]string{"entropy": strconv.FormatFloat(h, 'f', 2, 64)},
+		})
+	}
+	return found
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// exporter acumula os resultados e grava, ao final da busca, um documento
+// completo no formato de outra ferramenta (-export).
+type exporter interface {
+	add(f Finding)
+	write(w io.Writer) error
+}
+
+// Formatos aceitos por -export.
+var exportFormats = map[string]func(query string) exporter{
+	"misp":        newMISPExporter,
+	"stix":        newSTIXExporter,
+	"burp":        newBurpExporter,
+	"zap":         newZAPExporter,
+	"nuclei":      newNucleiExporter,
+	"remediation": newRemediationExporter,
+}
+
+// observable é um valor extraído já classificado para plataformas de
+// inteligência de ameaças.
+type observable struct {
+	kind    string // domain, url, ipv4, ipv6, email ou text
+	value   string
+	sources []string
+}
+
+// classify decide o tipo de observável de um resultado a partir do modo e do valor.
+func classify(f Finding) (string, string) {
+	value := f.Match
+	// host:porta (-with-ports) vira só o host.
+	if host, _, err := net.SplitHostPort(value); err == nil && f.Mode != "urls" {
+		value = host
+	}
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return "ipv4", value
+		}
+		return "ipv6", value
+	}
+	switch f.Mode {
+	case "urls":
+		return "url", value
+	case "domains", "rootdomains", "subdomains", "hostports", "internal":
+		return "domain", value
+	case "emails":
+		return "email", value
+	}
+	return "text", value
+}
+
+// observableSet agrupa os resultados por tipo e valor, mantendo a ordem em que
+// apareceram e as URLs dos arquivos onde foram encontrados.
+type observableSet struct {
+	list  []*observable
+	index map[string]*observable
+}
+
+func (s *observableSet) add(f Finding) {
+	kind, value := classify(f)
+	key := kind + "\x00" + value
+	if s.index == nil {
+		s.index = make(map[string]*observable)
+	}
+	o := s.index[key]
+	if o == nil {
+		o = &observable{kind: kind, value: value}
+		s.index[key] = o
+		s.list = append(s.list, o)
+	}
+	if !contains(o.sources, f.FileURL) {
+		o.sources = append(o.sources, f.FileURL)
+	}
+}
+
+// newUUID gera um UUID aleatório (versão 4).
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b[:])
+}
+
+// uuidV5 gera um UUID determinístico (versão 5) a partir de um namespace e um nome.
+func uuidV5(namespace, name string) string {
+	ns, _ := hex.DecodeString(strings.ReplaceAll(namespace, "-", ""))
+	h := sha1.New()
+	h.Write(ns)
+	h.Write([]byte(name))
+	sum := h.Sum(nil)[:16]
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return formatUUID(sum)
+}
+
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// mispExporter gera um evento MISP (formato JSON de importação de eventos).
+type mispExporter struct {
+	query string
+	set   observableSet
+}
+
+func newMISPExporter(query string) exporter {
+	return &mispExporter{query: query}
+}
+
+func (e *mispExporter) add(f Finding) {
+	e.set.add(f)
+}
+
+// Tipo e categoria MISP de cada tipo de observável.
+var mispTypes = map[string][2]string{
+	"domain": {"domain", "Network activity"},
+	"url":    {"url", "Network activity"},
+	"ipv4":   {"ip-dst", "Network activity"},
+	"ipv6":   {"ip-dst", "Network activity"},
+	"email":  {"email", "Payload delivery"},
+	"text":   {"text", "Other"},
+}
+
+func (e *mispExporter) write(w io.Writer) error {
+	type attribute struct {
+		UUID     string `json:"uuid"`
+		Type     string `json:"type"`
+		Category string `json:"category"`
+		Value    string `json:"value"`
+		ToIDS    bool   `json:"to_ids"`
+		Comment  string `json:"comment"`
+	}
+	attributes := make([]attribute, 0, len(e.set.list))
+	for _, o := range e.set.list {
+		t := mispTypes[o.kind]
+		attributes = append(attributes, attribute{
+			UUID:     newUUID(),
+			Type:     t[0],
+			Category: t[1],
+			Value:    o.value,
+			ToIDS:    false,
+			Comment:  "gfinder: " + strings.Join(o.sources, " "),
+		})
+	}
+
+	event := map[string]any{
+		"Event": map[string]any{
+			"uuid":            newUUID(),
+			"info":            "gfinder: " + e.query,
+			"date":            time.Now().UTC().Format("2006-01-02"),
+			"threat_level_id": "4",
+			"analysis":        "0",
+			"distribution":    "0",
+			"Attribute":       attributes,
+			"Tag":             []map[string]string{{"name": "tlp:amber"}},
+		},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(event)
+}
+
+// stixExporter gera um bundle STIX 2.1 com um objeto observável (SCO) por
+// valor e um grouping que reúne todos eles.
+type stixExporter struct {
+	query string
+	set   observableSet
+}
+
+func newSTIXExporter(query string) exporter {
+	return &stixExporter{query: query}
+}
+
+func (e *stixExporter) add(f Finding) {
+	e.set.add(f)
+}
+
+// Namespace definido pela especificação STIX 2.1 para IDs determinísticos de SCOs.
+const stixNamespace = "00abedb4-aa42-466c-9c01-fed23315a9b7"
+
+// Tipo STIX de cada tipo de observável; valores sem tipo padrão usam um SCO
+// customizado.
+var stixTypes = map[string]string{
+	"domain": "domain-name",
+	"url":    "url",
+	"ipv4":   "ipv4-addr",
+	"ipv6":   "ipv6-addr",
+	"email":  "email-addr",
+	"text":   "x-gfinder-match",
+}
+
+// stixID deriva o ID determinístico de um SCO, como manda a especificação:
+// UUIDv5 sobre o JSON canônico (JCS) das propriedades que o identificam. O
+// JCS não escapa &, < e >, ao contrário do json.Marshal, então URLs com query
+// string teriam outro ID que o gerado por outras ferramentas.
+func stixID(kind, value string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(map[string]string{"value": value})
+	return kind + "--" + uuidV5(stixNamespace, strings.TrimSuffix(b.String(), "\n"))
+}
+
+func (e *stixExporter) write(w io.Writer) error {
+	now := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	objects := make([]map[string]any, 0, len(e.set.list)+1)
+	refs := make([]string, 0, len(e.set.list))
+	for _, o := range e.set.list {
+		kind := stixTypes[o.kind]
+		id := stixID(kind, o.value)
+		objects = append(objects, map[string]any{
+			"type":              kind,
+			"spec_version":      "2.1",
+			"id":                id,
+			"value":             o.value,
+			"x_gfinder_sources": o.sources,
+		})
+		refs = append(refs, id)
+	}
+	if len(refs) > 0 {
+		objects = append(objects, map[string]any{
+			"type":         "grouping",
+			"spec_version": "2.1",
+			"id":           "grouping--" + newUUID(),
+			"created":      now,
+			"modified":     now,
+			"name":         "gfinder: " + e.query,
+			"context":      "suspicious-activity",
+			"object_refs":  refs,
+		})
+	}
+
+	bundle := map[string]any{
+		"type":    "bundle",
+		"id":      "bundle--" + newUUID(),
+		"objects": objects,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+// targetSet acumula os resultados das exportações de alvos HTTP (burp, zap e
+// nuclei). Os caminhos do modo endpoints são relativos: viram alvos nos hosts
+// encontrados no mesmo arquivo ou, se ele não tiver nenhum, no mesmo
+// repositório; sem host conhecido, ficam de fora.
+type targetSet struct {
+	observableSet
+	paths     []Finding
+	fileHosts map[string][]*url.URL
+	repoHosts map[string][]*url.URL
+}
+
+func (t *targetSet) add(f Finding) {
+	if f.Mode == "endpoints" {
+		t.paths = append(t.paths, f)
+		return
+	}
+	t.observableSet.add(f)
+	u := targetURL(classify(f))
+	if u == nil {
+		return
+	}
+	if t.fileHosts == nil {
+		t.fileHosts = make(map[string][]*url.URL)
+		t.repoHosts = make(map[string][]*url.URL)
+	}
+	base := &url.URL{Scheme: u.Scheme, Host: u.Host}
+	t.fileHosts[f.FileURL] = appendBase(t.fileHosts[f.FileURL], base)
+	if f.Repo != "" {
+		t.repoHosts[f.Repo] = appendBase(t.repoHosts[f.Repo], base)
+	}
+}
+
+func appendBase(list []*url.URL, base *url.URL) []*url.URL {
+	for _, u := range list {
+		if *u == *base {
+			return list
+		}
+	}
+	return append(list, base)
+}
+
+// targetURL converte um observável em alvo HTTP: URLs ficam como estão e
+// hosts (domínios e IPs) viram https://host/. Devolve nil para valores de
+// texto.
+func targetURL(kind, value string) *url.URL {
+	raw := value
+	switch kind {
+	case "url":
+		if strings.HasPrefix(raw, "//") {
+			raw = "https:" + raw
+		}
+	case "domain", "ipv4":
+		raw = "https://" + raw + "/"
+	case "ipv6":
+		raw = "https://[" + raw + "]/"
+	default:
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	return u
+}
+
+// targets devolve os alvos sem repetição: os dos observáveis, na ordem em que
+// apareceram, e depois os dos caminhos de endpoints.
+func (t *targetSet) targets() []*url.URL {
+	var targets []*url.URL
+	seen := make(map[string]bool)
+	add := func(u *url.URL) {
+		if !seen[u.String()] {
+			seen[u.String()] = true
+			targets = append(targets, u)
+		}
+	}
+	for _, o := range t.list {
+		if u := targetURL(o.kind, o.value); u != nil {
+			add(u)
+		}
+	}
+	for _, f := range t.paths {
+		ref, err := url.Parse(f.Match)
+		if err != nil {
+			continue
+		}
+		hosts := t.fileHosts[f.FileURL]
+		if len(hosts) == 0 && f.Repo != "" {
+			hosts = t.repoHosts[f.Repo]
+		}
+		for _, base := range hosts {
+			add(base.ResolveReference(ref))
+		}
+	}
+	return targets
+}
+
+// burpExporter gera uma configuração de projeto do Burp Suite com o escopo do
+// alvo (Target > Scope), carregável em Project options > Load. Alvos com
+// caminho (URLs e endpoints) restringem a regra ao caminho, em file.
+type burpExporter struct {
+	set targetSet
+}
+
+func newBurpExporter(string) exporter {
+	return &burpExporter{}
+}
+
+func (e *burpExporter) add(f Finding) {
+	e.set.add(f)
+}
+
+func (e *burpExporter) write(w io.Writer) error {
+	type scopeRule struct {
+		Enabled  bool   `json:"enabled"`
+		Protocol string `json:"protocol"`
+		Host     string `json:"host"`
+		Port     string `json:"port,omitempty"`
+		File     string `json:"file,omitempty"`
+	}
+	rules := []scopeRule{}
+	seen := make(map[scopeRule]bool)
+	for _, u := range e.set.targets() {
+		rule := scopeRule{
+			Enabled:  true,
+			Protocol: "any",
+			Host:     "^" + regexp.QuoteMeta(u.Hostname()) + "$",
+		}
+		if port := u.Port(); port != "" {
+			rule.Port = "^" + port + "$"
+		}
+		if path := u.EscapedPath(); path != "" && path != "/" {
+			rule.File = "^" + regexp.QuoteMeta(path) + ".*"
+		}
+		if seen[rule] {
+			continue
+		}
+		seen[rule] = true
+		rules = append(rules, rule)
+	}
+	config := map[string]any{
+		"target": map[string]any{
+			"scope": map[string]any{
+				"advanced_mode": true,
+				"include":       rules,
+				"exclude":       []scopeRule{},
+			},
+		},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config)
+}
+
+// zapExporter gera uma lista de URLs, uma por linha, para o OWASP ZAP
+// (Import > Import a File Containing URLs), que as adiciona à árvore de sites.
+type zapExporter struct {
+	set targetSet
+}
+
+func newZAPExporter(string) exporter {
+	return &zapExporter{}
+}
+
+func (e *zapExporter) add(f Finding) {
+	e.set.add(f)
+}
+
+func (e *zapExporter) write(w io.Writer) error {
+	for _, u := range e.set.targets() {
+		if _, err := fmt.Fprintln(w, u.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestSTIXID(t *testing.T) {
+	tests := []struct {
+		kind, value string
+		want        string
+	}{
+		// Mesmo ID que a biblioteca stix2 da OASIS gera para o domínio.
+		{"domain-name", "example.com", "domain-name--bedb4899-d24b-5401-bc86-8f6b4cc18ec7"},
+		// & e < entram como estão no JSON canônico, sem os escapes \u0026 e \u003c.
+		{"url", "https://example.com/?q=1&b=2", "url--acabb22e-964c-530f-abca-1d2577f9c364"},
+		{"x-gfinder-match", "<a>", "x-gfinder-match--15c65762-28b6-58d2-854c-ce53364cbe4d"},
+	}
+	for _, tt := range tests {
+		if got := stixID(tt.kind, tt.value); got != tt.want {
+			t.Errorf("stixID(%q, %q) = %q; quer %q", tt.kind, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Modos de extração aceitos por -m.
+var extractionModes = []string{"urls", "domains", "rootdomains", "emails", "ips", "subdomains", "s3", "buckets", "secrets", "jwt", "privatekeys", "creds", "endpoints", "params", "env", "webhooks", "hostports", "wallets", "internal", "docker", "packages", "graphql", "entropy", "firebase", "cidrs", "patterns"}
+
+// Regex interna para extração de URLs.
+var urlRegex = regexp.MustCompile(`((https?:\/\/|\/\/)[^\s"'<>]+)`)
+
+// Variante da regex de URLs que também aceita esquemas em maiúsculas (HTTPS://), usada com -i.
+var urlRegexFold = regexp.MustCompile(`(?i)` + urlRegex.String())
+
+// extractor reúne o modo de extração e as opções que afetam os valores extraídos.
+type extractor struct {
+	mode       string
+	filter     matcher
+	stripQuery bool
+	ignoreCase bool
+	printGroup string
+	joinLines  bool
+	// idnForm define como domínios internacionalizados são emitidos: "ascii"
+	// (xn--...) ou "unicode".
+	idnForm string
+	// withPorts mantém a porta no modo domains (host:port).
+	withPorts bool
+	// refang rearma indicadores desarmados (hxxp://, example[.]com) antes da extração.
+	refang bool
+	// strictDomains descarta hosts com sintaxe inválida ou TLD inexistente.
+	strictDomains bool
+	// unescape decodifica URLs escapadas em JSON, URL encoding e HTML antes da extração.
+	unescape bool
+	// scope, quando definido, descarta URLs e domínios fora do escopo.
+	scope *scope
+	// invert emite o que NÃO casa com a regex de filtro (-v-match).
+	invert bool
+	// publicIPs descarta, no modo ips, endereços privados e reservados.
+	publicIPs bool
+	// maskCreds troca as senhas por **** no modo creds.
+	maskCreds bool
+	// entropyThreshold e entropyMinLen são o limite de entropia (bits por
+	// caractere no base64) e o tamanho mínimo das strings do modo entropy.
+	entropyThreshold float64
+	entropyMinLen    int
+	// packs são os extratores dos pacotes de -patterns (modo patterns).
+	packs *patternPacks
+}
+
+// rule devolve o nome da regra que originou os valores extraídos.
+func (e *extractor) rule() string {
+	if e.mode == "" {
+		return "regex"
+	}
+	return e.mode
+}
+
+// extractDomain devolve o host de uma URL em minúsculas, sem porta, sem
+// colchetes de IPv6 literal (https://[2001:db8::1]:8443/ → 2001:db8::1) e sem
+// o ponto final de nomes absolutos.
+func extractDomain(rawURL string) string {
+	// Se a URL começar com //, adiciona "http:" para possibilitar o parse.
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "http:" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	// Hostname já separa a porta e remove os colchetes de endereços IPv6.
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		// Um IPv6 sem colchetes não é um host válido em URL; se o parse
+		// deixou passar, valida o endereço antes de aceitar.
+		if !strings.HasPrefix(u.Host, "[") || net.ParseIP(strings.SplitN(host, "%", 2)[0]) == nil {
+			return ""
+		}
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// Sufixos usados em redes internas, que não existem na internet pública.
+var internalSuffixes = []string{".local", ".internal", ".corp", ".intranet", ".lan", ".localdomain", ".home.arpa"}
+
+// isInternalHost indica se o host parece apontar para a rede interna de uma
+// organização: sufixos internos conhecidos ou nomes de um rótulo só
+// (http://jenkins/). Vazamentos desses hosts valem destaque.
+func isInternalHost(host string) bool {
+	if host == "" || host == "localhost" || net.ParseIP(strings.SplitN(host, "%", 2)[0]) != nil {
+		return false
+	}
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validHostname verifica a sintaxe de um nome de host (rótulos de 1 a 63
+// caracteres alfanuméricos ou hífen, sem hífen nas pontas, até 253 no total)
+// e se o TLD existe na Public Suffix List. Endereços IP são aceitos. Serve
+// para descartar identificadores de código que parecem domínios, como
+// foo.prototype.js ou this.state.value.
+func validHostname(host string) bool {
+	if net.ParseIP(strings.SplitN(host, "%", 2)[0]) != nil {
+		return true
+	}
+	host = normalizeIDN(host, "ascii")
+	if len(host) > 253 || !strings.Contains(host, ".") {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	// Sufixos fora da lista caem na regra padrão "*", que devolve só o último
+	// rótulo e icann == false; sufixos privados (github.io) têm mais de um rótulo.
+	suffix, icann := publicsuffix.PublicSuffix(host)
+	return icann || strings.Contains(suffix, ".")
+}
+
+// urlPort devolve a porta explícita de uma URL, ou "" quando não há.
+func urlPort(rawURL string) string {
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "http:" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Port()
+}
+
+// registrableDomain devolve o domínio registrável de um host segundo a Public
+// Suffix List: a.b.example.co.uk → example.co.uk, x.s3.amazonaws.com →
+// x.s3.amazonaws.com (o sufixo público é s3.amazonaws.com), user.github.io →
+// user.github.io. IPs e sufixos públicos puros não têm domínio registrável.
+func registrableDomain(host, idnForm string) string {
+	if host == "" || net.ParseIP(host) != nil {
+		return ""
+	}
+	root, err := publicsuffix.EffectiveTLDPlusOne(normalizeIDN(host, "ascii"))
+	if err != nil {
+		return ""
+	}
+	return normalizeIDN(root, idnForm)
+}
+
+// normalizeIDN converte um host internacionalizado para a forma escolhida em
+// -idn, para que o mesmo domínio em punycode e em Unicode seja um valor só.
+// Hosts que não são nomes válidos (ou IPs) voltam como estão.
+func normalizeIDN(host, form string) string {
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+	var converted string
+	var err error
+	if form == "unicode" {
+		converted, err = idna.Lookup.ToUnicode(host)
+	} else {
+		converted, err = idna.Lookup.ToASCII(host)
+	}
+	if err != nil {
+		return host
+	}
+	return converted
+}
+
+// trimURLPunctuation remove pontuação que costuma grudar no fim de URLs dentro
+// de código e texto. Parênteses e colchetes só saem se estiverem desbalanceados.
+func trimURLPunctuation(rawURL string) string {
+	for rawURL != "" {
+		last := rawURL[len(rawURL)-1]
+		switch last {
+		case '.', ',', ';', ':', '!', '?', '\'', '"', '`':
+		case ')':
+			if strings.Count(rawURL, "(") >= strings.Count(rawURL, ")") {
+				return rawURL
+			}
+		case ']':
+			if strings.Count(rawURL, "[") >= strings.Count(rawURL, "]") {
+				return rawURL
+			}
+		case '}':
+			if strings.Count(rawURL, "{") >= strings.Count(rawURL, "}") {
+				return rawURL
+			}
+		default:
+			return rawURL
+		}
+		rawURL = rawURL[:len(rawURL)-1]
+	}
+	return rawURL
+}
+
+// indexFold procura substr em s sem diferenciar maiúsculas e devolve o
+// intervalo em bytes de s, ou -1, -1. As posições vêm sempre de s: a forma
+// minúscula de alguns caracteres tem outro tamanho em bytes (Ⱥ vira ⱥ, o
+// sinal de Kelvin vira k), então buscar numa cópia em minúsculas desalinha.
+func indexFold(s, substr string) (int, int) {
+	if substr == "" {
+		return -1, -1
+	}
+	for start := range s {
+		i, j := start, 0
+		for i < len(s) && j < len(substr) {
+			r1, n1 := utf8.DecodeRuneInString(s[i:])
+			r2, n2 := utf8.DecodeRuneInString(substr[j:])
+			if unicode.ToLower(r1) != unicode.ToLower(r2) {
+				break
+			}
+			i, j = i+n1, j+n2
+		}
+		if j == len(substr) {
+			return start, i
+		}
+	}
+	return -1, -1
+}
+
+// canonicalURL normaliza uma URL extraída para que variações triviais do mesmo
+// endereço sejam deduplicadas: esquema e host em minúsculas, sem porta padrão,
+// sem fragmento e, opcionalmente, sem query string.
+func canonicalURL(rawURL string, stripQuery bool, idnForm string) string {
+	rawURL = trimURLPunctuation(rawURL)
+	schemeRelative := strings.HasPrefix(rawURL, "//")
+	parseURL := rawURL
+	if schemeRelative {
+		parseURL = "http:" + rawURL
+	}
+	u, err := url.Parse(parseURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	// O host vai em ASCII para o url.URL, que escaparia um host Unicode com
+	// %XX; a forma Unicode, se pedida, é recolocada no final.
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host, port = u.Host, ""
+	}
+	var unicodeHost string
+	if !strings.HasPrefix(u.Host, "[") {
+		asciiHost := normalizeIDN(host, "ascii")
+		if idnForm == "unicode" {
+			if unicodeHost = normalizeIDN(asciiHost, "unicode"); unicodeHost == asciiHost {
+				unicodeHost = ""
+			}
+		}
+		u.Host = asciiHost
+		if port != "" {
+			u.Host = net.JoinHostPort(asciiHost, port)
+		}
+	}
+	if host, port, err := net.SplitHostPort(u.Host); err == nil {
+		if (port == "80" && u.Scheme == "http") || (port == "443" && u.Scheme == "https") {
+			u.Host = host
+			if strings.Contains(host, ":") {
+				u.Host = "[" + host + "]"
+			}
+		}
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if stripQuery {
+		u.RawQuery = ""
+		u.ForceQuery = false
+	}
+
+	canonical := u.String()
+	if unicodeHost != "" {
+		canonical = strings.Replace(canonical, normalizeIDN(host, "ascii"), unicodeHost, 1)
+	}
+	if schemeRelative {
+		canonical = strings.TrimPrefix(canonical, "http:")
+	}
+	return canonical
+}
+
+// joinLines troca as quebras de linha por espaços, para que padrões escritos
+// para uma linha só encontrem valores quebrados em várias. Cada byte vira
+// exatamente um espaço, mantendo as posições das ocorrências no trecho.
+func joinLines(fragment string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, fragment)
+}
+
+// extraction é um valor extraído de um trecho, acompanhado dos grupos
+// nomeados capturados pela regex de filtro.
+type extraction struct {
+	Rule  string
+	Value string
+	// Start e End delimitam, em bytes, a ocorrência dentro do trecho.
+	Start  int
+	End    int
+	Groups map[string]string
+	// Severity e Tags destacam valores de interesse especial (ex: hosts internos).
+	Severity string
+	Tags     []string
+	// captures guarda todos os grupos por número, para -og N.
+	captures []string
+	// host é o domínio do valor nos modos de valueModes, usado no escopo, em
+	// -strict-domains e na marcação de hosts internos.
+	host string
+}
+
+// filterValue aplica a regex de filtro sobre um valor extraído pelo modo e
+// devolve os grupos nomeados da primeira ocorrência.
+func (e *extractor) filterValue(value string) (extraction, bool) {
+	if e.invert {
+		return extraction{Value: value}, !e.filter.MatchString(value)
+	}
+	matches := e.filter.FindAll(value)
+	if len(matches) == 0 {
+		return extraction{}, false
+	}
+	return extraction{Rule: matches[0].Rule, Value: value, Groups: namedGroups(e.filter, matches[0]), captures: matches[0].Groups}, true
+}
+
+// extract aplica o modo de extração sobre um trecho e devolve os valores
+// que passaram pela regex de filtro.
+func (e *extractor) extract(fragment string) []extraction {
+	if e.joinLines {
+		fragment = joinLines(fragment)
+	}
+	var values []extraction
+	if e.mode == "" && e.invert {
+		// Sem modo e invertido, o próprio trecho é o resultado.
+		if e.filter.MatchString(fragment) {
+			return nil
+		}
+		return []extraction{{Value: fragment, Start: 0, End: len(fragment)}}
+	}
+	if e.mode == "" {
+		// Sem modo, usa a regex passada para filtrar os trechos.
+		for _, m := range e.filter.FindAll(fragment) {
+			values = append(values, extraction{
+				Rule:     m.Rule,
+				Value:    m.Text,
+				Start:    m.Start,
+				End:      m.End,
+				Groups:   namedGroups(e.filter, m),
+				captures: m.Groups,
+			})
+		}
+		return e.selectGroup(values)
+	}
+
+	// URLs escapadas (https:\/\/..., https%3A%2F%2F...) são decodificadas antes
+	// da extração; as posições voltam depois para o trecho original.
+	var offsets []int
+	if e.unescape {
+		fragment, offsets = unescapeFragment(fragment)
+	}
+	if e.refang {
+		var refanged []int
+		fragment, refanged = refangFragment(fragment)
+		offsets = composeOffsets(offsets, refanged)
+	}
+
+	if find := valueModes[e.mode]; find != nil {
+		values = e.filterCandidates(find(e, fragment))
+	} else {
+		values = e.extractURLs(fragment)
+	}
+	if offsets != nil {
+		for i := range values {
+			values[i].Start, values[i].End = offsets[values[i].Start], offsets[values[i].End]
+		}
+	}
+	return e.selectGroup(values)
+}
+
+// filterCandidates aplica o escopo e a regex de filtro aos valores encontrados
+// por um modo de valueModes, mantendo a posição, a severidade, as tags e os
+// campos extras (Groups) deles.
+func (e *extractor) filterCandidates(candidates []extraction) []extraction {
+	var values []extraction
+	for _, c := range candidates {
+		if c.host != "" {
+			if e.scope != nil && !e.scope.match(c.host) {
+				continue
+			}
+			if e.strictDomains && !validHostname(c.host) {
+				continue
+			}
+			if isInternalHost(c.host) {
+				c.Severity = "high"
+				c.Tags = append(c.Tags, "internal")
+			}
+		}
+		x, ok := e.filterValue(c.Value)
+		if !ok {
+			continue
+		}
+		x.Start, x.End = c.Start, c.End
+		x.Severity, x.Tags = c.Severity, c.Tags
+		for name, v := range c.Groups {
+			if x.Groups == nil {
+				x.Groups = make(map[string]string)
+			}
+			x.Groups[name] = v
+		}
+		if x.Rule == "" {
+			x.Rule = c.Rule
+		}
+		values = append(values, x)
+	}
+	return values
+}
+
+// extractURLs encontra as URLs do trecho e emite, conforme o modo, a própria
+// URL ou o seu domínio.
+func (e *extractor) extractURLs(fragment string) []extraction {
+	var values []extraction
+	urlRe := urlRegex
+	if e.ignoreCase {
+		urlRe = urlRegexFold
+	}
+	for _, loc := range urlRe.FindAllStringIndex(fragment, -1) {
+		raw := fragment[loc[0]:loc[1]]
+		u := canonicalURL(raw, e.stripQuery, e.idnForm)
+		host := extractDomain(u)
+		if e.scope != nil && !e.scope.match(host) {
+			continue
+		}
+		if e.strictDomains && !validHostname(host) {
+			continue
+		}
+		before := len(values)
+		switch e.mode {
+		case "domains", "rootdomains":
+			// A URL canônica já traz o host normalizado (minúsculas, IDN).
+			domain := host
+			port := ""
+			if e.mode == "rootdomains" {
+				domain = registrableDomain(domain, e.idnForm)
+			} else if e.withPorts {
+				port = urlPort(u)
+			}
+			if domain != "" {
+				value := domain
+				if port != "" {
+					value = net.JoinHostPort(domain, port)
+				}
+				if x, ok := e.filterValue(value); ok {
+					// Posiciona a ocorrência no host como escrito no trecho, quando possível.
+					x.Start, x.End = loc[0], loc[1]
+					rawHost := extractDomain(trimURLPunctuation(raw))
+					if i, end := indexFold(raw, rawHost); i >= 0 {
+						x.Start, x.End = loc[0]+i, loc[0]+end
+						// A porta vem logo depois do host (ou do ] de um IPv6).
+						if port != "" && end <= len(raw) {
+							if j := strings.Index(raw[end:], ":"+port); j >= 0 && j <= 1 {
+								x.End += j + 1 + len(port)
+							}
+						}
+					}
+					values = append(values, x)
+				}
+			}
+		case "urls":
+			if x, ok := e.filterValue(u); ok {
+				x.Start, x.End = loc[0], loc[0]+len(trimURLPunctuation(raw))
+				values = append(values, x)
+			}
+		}
+		if isInternalHost(host) {
+			for i := before; i < len(values); i++ {
+				values[i].Severity = "high"
+				values[i].Tags = append(values[i].Tags, "internal")
+			}
+		}
+	}
+	return values
+}
+
+// selectGroup troca cada valor pela captura do grupo escolhido em -og
+// (número ou nome), descartando as ocorrências em que o grupo não participou.
+func (e *extractor) selectGroup(values []extraction) []extraction {
+	if e.printGroup == "" {
+		return values
+	}
+	index, numeric := strconv.Atoi(e.printGroup)
+	selected := values[:0]
+	for _, x := range values {
+		g := x.Groups[e.printGroup]
+		if numeric == nil && index < len(x.captures) {
+			g = x.captures[index]
+		}
+		if g != "" {
+			x.Value = g
+			selected = append(selected, x)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestExtractDomain(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"simples", "https://api.example.com/v1", "api.example.com"},
+		{"com porta", "https://api.example.com:8443/v1", "api.example.com"},
+		{"porta vazia", "http://example.com:/path", "example.com"},
+		{"maiúsculas", "HTTPS://API.Example.COM/path", "api.example.com"},
+		{"sem esquema", "//cdn.example.com/app.js", "cdn.example.com"},
+		{"ponto final", "https://example.com./", "example.com"},
+		{"IPv6 com colchetes", "https://[2001:db8::1]/", "2001:db8::1"},
+		{"IPv6 com colchetes e porta", "https://[2001:db8::1]:8443/", "2001:db8::1"},
+		{"IPv6 em maiúsculas", "http://[2001:DB8::ABCD]:80/", "2001:db8::abcd"},
+		{"IPv6 com colchetes e porta vazia", "http://[::1]:/", "::1"},
+		{"IPv6 sem colchetes", "http://2001:db8::1/", ""},
+		{"IPv6 sem colchetes com porta", "http://2001:db8::1:8080/", ""},
+		{"colchetes sem IPv6", "http://[not-an-ip]/", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractDomain(tt.url); got != tt.want {
+				t.Errorf("extractDomain(%q) = %q; quer %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexFold(t *testing.T) {
+	tests := []struct {
+		name       string
+		s, substr  string
+		start, end int
+	}{
+		{"igual", "api.example.com", "example.com", 4, 15},
+		{"maiúsculas", "HTTPS://API.Example.COM/x", "api.example.com", 8, 23},
+		{"ausente", "https://example.org", "example.com", -1, -1},
+		{"vazio", "example.com", "", -1, -1},
+		// Ⱥ tem 2 bytes e ⱥ, 3: as posições seguem os bytes de s.
+		{"minúscula mais longa", "see http://ȺȺ.com/x", "ⱥⱥ.com", 11, 19},
+		// O sinal de Kelvin tem 3 bytes e vira um k de 1 byte.
+		{"sinal de Kelvin", "http://\u212aey.io", "key.io", 7, 15},
+		{"no fim", "abc", "abcd", -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := indexFold(tt.s, tt.substr)
+			if start != tt.start || end != tt.end {
+				t.Errorf("indexFold(%q, %q) = %d, %d; quer %d, %d", tt.s, tt.substr, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+// newTestExtractor monta um extrator do modo com o filtro "." e as opções
+// padrão da linha de comando.
+func newTestExtractor(mode string) *extractor {
+	return &extractor{filter: re2Matcher{regexp.MustCompile(".")}, mode: mode, idnForm: "ascii", unescape: true}
+}
+
+func TestExtractDomainsPosition(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragment  string
+		withPorts bool
+		want      []string
+	}{
+		{"host simples", "see https://api.example.com/x", false, []string{"api.example.com"}},
+		{"maiúsculas", "see HTTPS://API.Example.COM/x", false, []string{"API.Example.COM"}},
+		// Ⱥ tem 2 bytes e ⱥ, 3: a busca numa cópia em minúsculas saía do trecho.
+		{"minúscula mais longa", "see http://ȺȺȺȺ.com/x", false, []string{"ȺȺȺȺ.com"}},
+		{"minúscula mais longa com porta", "see http://ȺȺȺȺ.com:8443/x", true, []string{"ȺȺȺȺ.com:8443"}},
+		{"sinal de Kelvin", "go http://\u212aey.io/ now", false, []string{"\u212aey.io"}},
+		{"porta", "curl https://Api.Example.com:8443/v1", true, []string{"Api.Example.com:8443"}},
+		{"IPv6 com porta", "http://[2001:db8::1]:8080/", true, []string{"2001:db8::1]:8080"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestExtractor("domains")
+			e.withPorts = tt.withPorts
+			values := e.extract(tt.fragment)
+			if len(values) != len(tt.want) {
+				t.Fatalf("%d resultados; quer %d", len(values), len(tt.want))
+			}
+			for i, x := range values {
+				if x.Start < 0 || x.End > len(tt.fragment) || x.Start > x.End {
+					t.Fatalf("posição [%d:%d] fora do trecho de %d bytes", x.Start, x.End, len(tt.fragment))
+				}
+				if got := tt.fragment[x.Start:x.End]; got != tt.want[i] {
+					t.Errorf("trecho[%d:%d] = %q; quer %q", x.Start, x.End, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractOffsetsMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		refang   bool
+		fragment string
+		want     []string
+	}{
+		{"JSON", "urls", false, `u = "https:\/\/api.example.com\/v1"`, []string{`https:\/\/api.example.com\/v1`}},
+		{"JSON multibyte", "urls", false, `"ação": "https:\/\/ȺȺ.com\/x"`, []string{`https:\/\/ȺȺ.com\/x`}},
+		{"URL encoding", "urls", false, "r=https%3A%2F%2Fa.io%2Fb z", []string{"https%3A%2F%2Fa.io%2Fb"}},
+		{"entidades HTML", "urls", false, `<a href="https:&#x2F;&#x2F;a.io&sol;p">`, []string{"https:&#x2F;&#x2F;a.io&sol;p"}},
+		{"desarmado", "urls", true, "é hxxp://Acme[.]com/x", []string{"hxxp://Acme[.]com/x"}},
+		{"desarmado com Kelvin", "domains", true, "\u212a\u212a hxxps://\u212aey[.]io/a", []string{"\u212aey[.]io"}},
+		{"escape e desarme", "domains", true, `"hxxps:\/\/ȺȺ[.]com\/x"`, []string{"ȺȺ[.]com"}},
+		{"domínio codificado", "domains", false, "u=https%3A%2F%2FApi.Example.COM%2Fx", []string{"Api.Example.COM"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestExtractor(tt.mode)
+			e.refang = tt.refang
+			values := e.extract(tt.fragment)
+			if len(values) != len(tt.want) {
+				t.Fatalf("%d resultados; quer %d", len(values), len(tt.want))
+			}
+			for i, x := range values {
+				if x.Start < 0 || x.End > len(tt.fragment) || x.Start > x.End {
+					t.Fatalf("posição [%d:%d] fora do trecho de %d bytes", x.Start, x.End, len(tt.fragment))
+				}
+				if got := tt.fragment[x.Start:x.End]; got != tt.want[i] {
+					t.Errorf("trecho[%d:%d] = %q; quer %q", x.Start, x.End, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// firebaseDBRegex encontra bancos do Realtime Database: name.firebaseio.com e
+// name-default-rtdb.região.firebasedatabase.app. O nome fica no grupo 1.
+var firebaseDBRegex = regexp.MustCompile(`(?i)\b([a-z0-9][a-z0-9\-]{0,62})\.(?:firebaseio\.com|[a-z0-9\-]+\.firebasedatabase\.app)\b`)
+
+// firebaseKeyRegex encontra chaves de API do Google (AIza...), que nos
+// projetos Firebase aparecem na configuração do app web e no
+// google-services.json.
+var firebaseKeyRegex = regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`)
+
+// firebaseFields são os campos que o modo firebase acrescenta a cada resultado.
+var firebaseFields = []string{"project"}
+
+// findFirebase extrai as URLs de bancos do Firebase, como
+// https://nome.firebaseio.com, prontas para testar o acesso em /.json, e as
+// chaves de API dos trechos que mencionam o Firebase, onde a chave do
+// Google pertence ao projeto. A tag indica o tipo: database ou api-key.
+func findFirebase(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, m := range firebaseDBRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		host := strings.ToLower(fragment[m[0]:m[1]])
+		project := strings.TrimSuffix(strings.ToLower(fragment[m[2]:m[3]]), "-default-rtdb")
+		found = append(found, extraction{
+			Rule:     "firebase-database",
+			Value:    "https://" + host,
+			Start:    m[0],
+			End:      m[1],
+			Severity: "medium",
+			Tags:     []string{"database"},
+			Groups:   map[string]string{"project": project},
+			host:     host,
+		})
+	}
+	if strings.Contains(strings.ToLower(fragment), "firebase") {
+		for _, loc := range firebaseKeyRegex.FindAllStringIndex(fragment, -1) {
+			found = append(found, extraction{
+				Rule:     "firebase-api-key",
+				Value:    fragment[loc[0]:loc[1]],
+				Start:    loc[0],
+				End:      loc[1],
+				Severity: "medium",
+				Tags:     []string{"api-key"},
+			})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
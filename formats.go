@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats são os formatos aceitos em -o no lugar de um arquivo. Cada
+// formato recebe os resultados como um exporter: os que podem ser gravados
+// linha a linha escrevem já no add, e os demais montam o documento no flush.
+var outputFormats = map[string]func(w io.Writer, fields []string) exporter{
+	"json":     newJSONFormat,
+	"csv":      newCSVFormat,
+	"grep":     newGrepFormat,
+	"markdown": newMarkdownFormat,
+	"sarif":    newSARIFFormat,
+	"table":    newTableFormat,
+	"tsv":      newTSVFormat,
+	"yaml":     newYAMLFormat,
+}
+
+// formatExtensions associa extensões de arquivo de -o aos formatos.
+var formatExtensions = map[string]string{
+	".json":  "json",
+	".csv":   "csv",
+	".md":    "markdown",
+	".sarif": "sarif",
+	".tsv":   "tsv",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+}
+
+// formatNames lista os formatos de -o em ordem alfabética, para mensagens.
+func formatNames() string {
+	names := make([]string, 0, len(outputFormats))
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// formatForPath deduz o formato pela extensão do arquivo, ignorando um .gz
+// final; devolve "" para o texto padrão.
+func formatForPath(path string) string {
+	path = strings.TrimSuffix(strings.ToLower(path), ".gz")
+	return formatExtensions[filepath.Ext(path)]
+}
+
+// jsonFormat grava os resultados como um único array JSON, um objeto por
+// elemento, escrito à medida que os resultados chegam. Com -fields, cada
+// objeto traz só os campos pedidos, na mesma ordem.
+type jsonFormat struct {
+	w      io.Writer
+	fields []string
+	count  int
+	err    error
+}
+
+func newJSONFormat(w io.Writer, fields []string) exporter {
+	return &jsonFormat{w: w, fields: fields}
+}
+
+func (j *jsonFormat) add(f Finding) {
+	if j.err != nil {
+		return
+	}
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+	var v any = f
+	if len(j.fields) > 0 {
+		v = projectFinding(f, j.fields)
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("  ", "  ")
+	if j.err = enc.Encode(v); j.err != nil {
+		return
+	}
+	_, j.err = io.WriteString(j.w, sep+"  "+strings.TrimSuffix(b.String(), "\n"))
+}
+
+// projection é um objeto JSON com só os campos de -fields, na ordem pedida.
+// As chaves são as do resultado completo (url vira file_url, como no CSV).
+type projection struct {
+	keys   []string
+	values []any
+}
+
+func projectFinding(f Finding, fields []string) projection {
+	p := projection{keys: make([]string, len(fields)), values: make([]any, len(fields))}
+	for i, name := range fields {
+		p.keys[i] = name
+		if h, ok := csvHeader[name]; ok {
+			p.keys[i] = h
+		}
+		p.values[i] = jsonFieldValue(f, name)
+	}
+	return p
+}
+
+// jsonFieldValue devolve o campo com o mesmo tipo do JSON completo: números
+// para as posições, a página e as estrelas, e uma lista para as tags.
+func jsonFieldValue(f Finding, name string) any {
+	switch name {
+	case "line":
+		return f.Line
+	case "start":
+		return f.Start
+	case "end":
+		return f.End
+	case "page":
+		return f.Page
+	case "stars":
+		return f.Stars
+	case "tags":
+		if f.Tags == nil {
+			return []string{}
+		}
+		return f.Tags
+	}
+	return fieldValue(f, name)
+}
+
+func (p projection) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	b.WriteByte('{')
+	for i, key := range p.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+		b.WriteByte(':')
+		if err := enc.Encode(p.values[i]); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (j *jsonFormat) write(w io.Writer) error {
+	if j.err != nil {
+		return j.err
+	}
+	if j.count == 0 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
+
+// csvColumns são as colunas padrão de -o csv; -fields as substitui.
+var csvColumns = []string{"repo", "url", "match", "mode", "page"}
+
+// csvHeader é o nome de cada campo no cabeçalho, igual ao das chaves do JSON.
+var csvHeader = map[string]string{"url": "file_url"}
+
+// csvFormat grava uma linha CSV por resultado, com cabeçalho e o escape do
+// encoding/csv (aspas e quebras de linha dentro dos valores).
+type csvFormat struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func newCSVFormat(w io.Writer, fields []string) exporter {
+	columns := fields
+	if len(columns) == 0 {
+		columns = csvColumns
+	}
+	c := &csvFormat{w: csv.NewWriter(w), columns: columns}
+	header := make([]string, len(columns))
+	for i, name := range columns {
+		header[i] = name
+		if h, ok := csvHeader[name]; ok {
+			header[i] = h
+		}
+	}
+	c.w.Write(header)
+	return c
+}
+
+func (c *csvFormat) add(f Finding) {
+	row := make([]string, len(c.columns))
+	for i, name := range c.columns {
+		row[i] = fieldValue(f, name)
+	}
+	c.w.Write(row)
+}
+
+func (c *csvFormat) write(io.Writer) error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// markdownFormat grava uma tabela em Markdown com o arquivo como link, pronta
+// para colar em issues, wikis e relatórios de bug bounty.
+type markdownFormat struct {
+	w     io.Writer
+	count int
+	err   error
+}
+
+func newMarkdownFormat(w io.Writer, _ []string) exporter {
+	return &markdownFormat{w: w}
+}
+
+func (m *markdownFormat) add(f Finding) {
+	if m.err != nil {
+		return
+	}
+	var b strings.Builder
+	if m.count == 0 {
+		b.WriteString("| Repo | File | Match | Mode |\n|------|------|-------|------|\n")
+	}
+	m.count++
+	// Parênteses e espaços no endereço encerrariam o link antes da hora.
+	link := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(f.FileURL)
+	fmt.Fprintf(&b, "| %s | [%s](%s) | `%s` | %s |\n", markdownCell(f.Repo), markdownCell(f.Path), link, markdownCell(f.Match), f.Mode)
+	_, m.err = io.WriteString(m.w, b.String())
+}
+
+func (m *markdownFormat) write(w io.Writer) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.count == 0 {
+		_, err := io.WriteString(w, "_Nenhum resultado._\n")
+		return err
+	}
+	return nil
+}
+
+// tsvColumns são as colunas padrão de -o tsv e -o table; -fields as substitui.
+var tsvColumns = []string{"url", "match"}
+
+// tsvEscape troca tabs e quebras de linha pelas sequências \t, \n e \r, para
+// cada resultado ocupar uma linha com um valor por coluna.
+var tsvEscape = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// tsvFormat grava uma linha por resultado com as colunas separadas por tab,
+// sem cabeçalho, para cut, awk e sort -t.
+type tsvFormat struct {
+	w       io.Writer
+	columns []string
+	err     error
+}
+
+func newTSVFormat(w io.Writer, fields []string) exporter {
+	if len(fields) == 0 {
+		fields = tsvColumns
+	}
+	return &tsvFormat{w: w, columns: fields}
+}
+
+func (t *tsvFormat) add(f Finding) {
+	if t.err != nil {
+		return
+	}
+	row := make([]string, len(t.columns))
+	for i, name := range t.columns {
+		row[i] = tsvEscape.Replace(fieldValue(f, name))
+	}
+	_, t.err = io.WriteString(t.w, strings.Join(row, "\t")+"\n")
+}
+
+func (t *tsvFormat) write(io.Writer) error {
+	return t.err
+}
+
+// tableSpace troca tabs e quebras de linha, que desalinhariam a tabela, por espaços.
+var tableSpace = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+// tableFormat alinha as colunas para leitura no terminal. A largura depende
+// de todos os valores, então a tabela só sai no flush.
+type tableFormat struct {
+	tw      *tabwriter.Writer
+	columns []string
+}
+
+func newTableFormat(w io.Writer, fields []string) exporter {
+	if len(fields) == 0 {
+		fields = tsvColumns
+	}
+	t := &tableFormat{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0), columns: fields}
+	header := make([]string, len(fields))
+	for i, name := range fields {
+		header[i] = strings.ToUpper(name)
+		if h, ok := csvHeader[name]; ok {
+			header[i] = strings.ToUpper(h)
+		}
+	}
+	fmt.Fprintln(t.tw, strings.Join(header, "\t"))
+	return t
+}
+
+func (t *tableFormat) add(f Finding) {
+	row := make([]string, len(t.columns))
+	for i, name := range t.columns {
+		row[i] = tableSpace.Replace(fieldValue(f, name))
+	}
+	fmt.Fprintln(t.tw, strings.Join(row, "\t"))
+}
+
+func (t *tableFormat) write(io.Writer) error {
+	return t.tw.Flush()
+}
+
+// yamlMatch, yamlFile e yamlRepo formam o documento de -o yaml: repositórios,
+// com seus arquivos, cada um com a lista de ocorrências.
+type yamlMatch struct {
+	Match    string            `yaml:"match"`
+	Mode     string            `yaml:"mode"`
+	Rule     string            `yaml:"rule,omitempty"`
+	Line     int               `yaml:"line"`
+	Severity string            `yaml:"severity,omitempty"`
+	Tags     []string          `yaml:"tags,omitempty"`
+	Groups   map[string]string `yaml:"groups,omitempty"`
+}
+
+type yamlFile struct {
+	Path    string      `yaml:"path"`
+	URL     string      `yaml:"url"`
+	Owner   string      `yaml:"owner,omitempty"`
+	Matches []yamlMatch `yaml:"matches"`
+}
+
+type yamlRepo struct {
+	Repo  string      `yaml:"repo"`
+	Files []*yamlFile `yaml:"files"`
+}
+
+// yamlFormat agrupa os resultados por repositório e arquivo, na ordem em que
+// aparecem, e grava o documento no flush.
+type yamlFormat struct {
+	repos  []*yamlRepo
+	byRepo map[string]*yamlRepo
+	byFile map[string]*yamlFile
+}
+
+func newYAMLFormat(io.Writer, []string) exporter {
+	return &yamlFormat{byRepo: make(map[string]*yamlRepo), byFile: make(map[string]*yamlFile)}
+}
+
+func (y *yamlFormat) add(f Finding) {
+	repo := y.byRepo[f.Repo]
+	if repo == nil {
+		repo = &yamlRepo{Repo: f.Repo}
+		y.byRepo[f.Repo] = repo
+		y.repos = append(y.repos, repo)
+	}
+	file := y.byFile[f.FileURL]
+	if file == nil {
+		file = &yamlFile{Path: f.Path, URL: f.FileURL, Owner: f.Owner}
+		y.byFile[f.FileURL] = file
+		repo.Files = append(repo.Files, file)
+	}
+	file.Matches = append(file.Matches, yamlMatch{
+		Match:    f.Match,
+		Mode:     f.Mode,
+		Rule:     f.Rule,
+		Line:     f.Line,
+		Severity: f.Severity,
+		Tags:     f.Tags,
+		Groups:   f.Groups,
+	})
+}
+
+func (y *yamlFormat) write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	repos := y.repos
+	if repos == nil {
+		repos = []*yamlRepo{}
+	}
+	if err := enc.Encode(repos); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// grepFormat imita o grep -rn: repo/caminho:posição:valor, com a posição em
+// bytes da ocorrência dentro do trecho devolvido pela API (a API não informa
+// a linha no arquivo).
+type grepFormat struct {
+	w   io.Writer
+	err error
+}
+
+func newGrepFormat(w io.Writer, _ []string) exporter {
+	return &grepFormat{w: w}
+}
+
+func (g *grepFormat) add(f Finding) {
+	if g.err != nil {
+		return
+	}
+	name := f.Path
+	if f.Repo != "" {
+		name = f.Repo + "/" + f.Path
+	}
+	_, g.err = fmt.Fprintf(g.w, "%s:%d:%s\n", name, f.Start, tableSpace.Replace(f.Match))
+}
+
+func (g *grepFormat) write(io.Writer) error {
+	return g.err
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ghKeyringPrefix antecede o host no serviço em que o gh CLI guarda o token
+// no chaveiro (ex: gh:github.com).
+const ghKeyringPrefix = "gh:"
+
+// No macOS, a biblioteca de chaveiro do gh (go-keyring) grava o segredo
+// codificado, com um destes prefixos: base64 nas versões atuais e
+// hexadecimal nas antigas.
+const (
+	keyringBase64Prefix = "go-keyring-base64:"
+	keyringHexPrefix    = "go-keyring-encoded:"
+)
+
+// ghToken procura o token do gh CLI já autenticado no host (github.com ou o
+// servidor do Enterprise): pelo próprio gh (gh auth token), pelo hosts.yml da
+// configuração dele ou pelo chaveiro do sistema. Devolve "" se nenhum for
+// encontrado.
+func ghToken(host string) string {
+	if out, err := exec.Command("gh", "auth", "token", "--hostname", host).Output(); err == nil {
+		if t := strings.TrimSpace(string(out)); t != "" {
+			return t
+		}
+	}
+	if t := ghHostsToken(ghConfigDir(), host); t != "" {
+		return t
+	}
+	return keyringToken(ghKeyringPrefix + host)
+}
+
+// ghConfigDir segue a mesma ordem do gh: GH_CONFIG_DIR, XDG_CONFIG_HOME/gh e
+// ~/.config/gh.
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gh")
+}
+
+// ghHostsToken lê o oauth_token do host no hosts.yml do gh. O arquivo é
+// simples o bastante para dispensar um parser de YAML: um host por chave no
+// primeiro nível e os campos indentados abaixo dele.
+func ghHostsToken(dir, host string) string {
+	file, err := os.Open(filepath.Join(dir, "hosts.yml"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	inHost := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			inHost = strings.TrimSpace(line) == host+":"
+			continue
+		}
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ":"); inHost && ok && key == "oauth_token" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// keyringToken consulta o chaveiro do sistema pelas ferramentas nativas:
+// security no macOS e secret-tool (Secret Service) no Linux.
+func keyringToken(service string) string {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service)
+	default:
+		return ""
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return decodeKeyringSecret(strings.TrimSpace(string(out)))
+}
+
+// decodeKeyringSecret desfaz a codificação do go-keyring; segredos sem os
+// prefixos dele voltam como estão.
+func decodeKeyringSecret(secret string) string {
+	var decoded []byte
+	var err error
+	switch {
+	case strings.HasPrefix(secret, keyringBase64Prefix):
+		decoded, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, keyringBase64Prefix))
+	case strings.HasPrefix(secret, keyringHexPrefix):
+		decoded, err = hex.DecodeString(strings.TrimPrefix(secret, keyringHexPrefix))
+	default:
+		return secret
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(decoded))
+}
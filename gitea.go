@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// giteaSearcher busca código num servidor Gitea ou Forgejo (-provider gitea,
+// com a raiz em -base-url). Essas forjas não têm busca de código na API, só
+// na interface web (com o indexador de código ativo), então a página de
+// resultados é lida como HTML. Com -org, a busca fica restrita à organização
+// ou ao usuário (Gitea 1.22+); repositórios privados exigem o cookie de uma
+// sessão em GITEA_COOKIE.
+type giteaSearcher struct {
+	baseURL *url.URL
+	org     string
+	cookie  string
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+}
+
+func newGiteaSearcher(baseURL, org, cookie string, client *githubClient) (*giteaSearcher, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("endereço inválido: %q", baseURL)
+	}
+	return &giteaSearcher{baseURL: u, org: org, cookie: cookie, client: client}, nil
+}
+
+// hasClass indica se o elemento tem a classe CSS.
+func hasClass(n *html.Node, class string) bool {
+	for _, a := range n.Attr {
+		if a.Key == "class" {
+			return contains(strings.Fields(a.Val), class)
+		}
+	}
+	return false
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent junta o texto de todos os nós abaixo do elemento, sem as tags
+// do realce de sintaxe.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// findElements devolve, em ordem, os elementos abaixo de n que satisfazem match.
+func findElements(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && match(c) {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, findElements(c, match)...)
+	}
+	return found
+}
+
+func (g *giteaSearcher) searchCode(query string, page, _ int) (*CodeSearchResult, error) {
+	path := "explore/code"
+	if g.org != "" {
+		path = url.PathEscape(g.org) + "/-/code"
+	}
+	// fuzzy=false (até o 1.21) e mode=exact (1.22+) pedem a busca exata.
+	pageURL := g.baseURL.ResolveReference(&url.URL{Path: path, RawQuery: fmt.Sprintf("q=%s&fuzzy=false&mode=exact&page=%d", url.QueryEscape(query), page)})
+	req, err := http.NewRequest("GET", pageURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	if g.cookie != "" {
+		req.Header.Set("Cookie", g.cookie)
+	}
+	status, _, body, err := g.client.fetchRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%s retornou status %d", pageURL.Host, status)
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("ler HTML: %w", err)
+	}
+
+	// Cada resultado traz os links do repositório e do arquivo no cabeçalho e
+	// as linhas encontradas numa tabela. Converte para o formato da API do
+	// GitHub, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{}
+	isAnchor := func(n *html.Node) bool { return n.Data == "a" }
+	for _, r := range findElements(doc, func(n *html.Node) bool { return hasClass(n, "repo-search-result") }) {
+		var item CodeSearchItem
+		var previous string
+		for _, a := range findElements(r, isAnchor) {
+			href := attrValue(a, "href")
+			if strings.Contains(href, "/src/") {
+				fileURL, err := g.baseURL.Parse(href)
+				if err != nil {
+					break
+				}
+				item.HTMLURL = fileURL.String()
+				item.Path = strings.TrimSpace(textContent(a))
+				item.Repository.FullName = previous
+				break
+			}
+			previous = strings.TrimSpace(textContent(a))
+		}
+		if item.HTMLURL == "" {
+			continue
+		}
+		var lines []string
+		for _, td := range findElements(r, func(n *html.Node) bool { return n.Data == "td" && hasClass(n, "lines-code") }) {
+			lines = append(lines, strings.TrimRight(textContent(td), "\r\n"))
+		}
+		item.TextMatches = []TextMatch{{Fragment: strings.Join(lines, "\n")}}
+		result.Items = append(result.Items, item)
+	}
+	result.TotalCount = len(result.Items)
+	// A paginação não informa o total; a busca acaba quando não há link para
+	// a próxima página.
+	next := fmt.Sprintf("page=%d", page+1)
+	result.last = len(findElements(doc, func(n *html.Node) bool {
+		return n.Data == "a" && strings.Contains(attrValue(n, "href")+"&", next+"&")
+	})) == 0
+
+	if err := g.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
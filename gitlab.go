@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// gitlabURL é a instância padrão de -provider gitlab; -gitlab-url aponta para
+// instâncias próprias.
+const gitlabURL = "https://gitlab.com"
+
+// gitlabSearcher usa a busca da API do GitLab com o escopo blobs (-provider
+// gitlab). Com -org, a busca fica restrita ao grupo.
+type gitlabSearcher struct {
+	baseURL string
+	token   string
+	group   string
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+	// projects guarda o nome e o endereço de cada projeto, que a busca só
+	// informa pelo ID.
+	projects map[int]gitlabProject
+}
+
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+// gitlabBlob é um resultado da busca com escopo blobs.
+type gitlabBlob struct {
+	Path      string `json:"path"`
+	Ref       string `json:"ref"`
+	Data      string `json:"data"`
+	ProjectID int    `json:"project_id"`
+}
+
+func newGitLabSearcher(baseURL, token, group string, client *githubClient) *gitlabSearcher {
+	return &gitlabSearcher{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		group:    group,
+		client:   client,
+		projects: make(map[int]gitlabProject),
+	}
+}
+
+// get faz um GET autenticado na API v4 da instância.
+func (g *gitlabSearcher) get(path string, v any) (http.Header, error) {
+	req, err := http.NewRequest("GET", g.baseURL+"/api/v4"+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+	return g.client.fetchJSON(req, v)
+}
+
+// project devolve o projeto pelo ID, consultando a API só na primeira vez.
+func (g *gitlabSearcher) project(id int) (gitlabProject, error) {
+	if p, ok := g.projects[id]; ok {
+		return p, nil
+	}
+	var p gitlabProject
+	if _, err := g.get(fmt.Sprintf("/projects/%d", id), &p); err != nil {
+		return p, fmt.Errorf("projeto %d: %w", id, err)
+	}
+	g.projects[id] = p
+	return p, nil
+}
+
+func (g *gitlabSearcher) searchCode(query string, page, perPage int) (*CodeSearchResult, error) {
+	path := "/search"
+	if g.group != "" {
+		path = "/groups/" + url.PathEscape(g.group) + "/search"
+	}
+	var blobs []gitlabBlob
+	header, err := g.get(fmt.Sprintf("%s?scope=blobs&search=%s&page=%d&per_page=%d", path, url.QueryEscape(query), page, perPage), &blobs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Converte para o formato da API do GitHub, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{}
+	result.TotalCount, _ = strconv.Atoi(header.Get("X-Total"))
+	for _, b := range blobs {
+		p, err := g.project(b.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		item := CodeSearchItem{
+			Path:    b.Path,
+			HTMLURL: fmt.Sprintf("%s/-/blob/%s/%s", p.WebURL, b.Ref, b.Path),
+		}
+		item.Repository.FullName = p.PathWithNamespace
+		item.TextMatches = []TextMatch{{Fragment: b.Data}}
+		result.Items = append(result.Items, item)
+	}
+	// O GitLab informa a próxima página no cabeçalho; sem ela, a busca acabou.
+	result.last = header.Get("X-Next-Page") == ""
+
+	if err := g.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -1,3 +1,11 @@
 module github.com/gilsgil/gfinder
 
 go 1.23.3
+
+require github.com/dlclark/regexp2 v1.12.0
+
+require (
+	golang.org/x/net v0.34.0
+	golang.org/x/text v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
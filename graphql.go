@@ -0,0 +1,88 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// graphqlSegments são os segmentos de caminho que indicam um endpoint GraphQL
+// (/graphql, /api/gql, /graphql.php) ou uma IDE exposta (/graphiql).
+var graphqlSegments = []string{"graphql", "graphiql", "gql"}
+
+// introspectionRegex encontra consultas de introspecção: a IntrospectionQuery
+// padrão dos clientes e as seleções __schema { e __type(.
+var introspectionRegex = regexp.MustCompile(`\bIntrospectionQuery\b|\b__schema\s*\{|\b__type\s*\(`)
+
+// graphqlRoute indica se o caminho tem um segmento GraphQL e devolve a tag:
+// graphiql para a IDE, endpoint para os demais.
+func graphqlRoute(route string) (string, bool) {
+	for _, segment := range strings.Split(strings.ToLower(route), "/") {
+		name, _, _ := strings.Cut(segment, ".")
+		if contains(graphqlSegments, name) {
+			if name == "graphiql" {
+				return "graphiql", true
+			}
+			return "endpoint", true
+		}
+	}
+	return "", false
+}
+
+// findGraphQL extrai endpoints GraphQL, em URLs completas ou em caminhos
+// relativos como os do modo endpoints, e aponta consultas de introspecção no
+// trecho, que indicam um schema aberto a quem perguntar. A tag diz o que foi
+// encontrado: endpoint, graphiql ou introspection.
+func findGraphQL(e *extractor, fragment string) []extraction {
+	var found []extraction
+	urlRe := urlRegex
+	if e.ignoreCase {
+		urlRe = urlRegexFold
+	}
+	for _, loc := range urlRe.FindAllStringIndex(fragment, -1) {
+		raw := trimURLPunctuation(fragment[loc[0]:loc[1]])
+		host := extractDomain(raw)
+		rest := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(raw, "https:"), "http:"), "//")
+		_, path, _ := strings.Cut(rest, "/")
+		path, _, _ = strings.Cut(path, "?")
+		tag, ok := graphqlRoute(path)
+		if host == "" || !ok {
+			continue
+		}
+		value := raw
+		if e.stripQuery {
+			value, _, _ = strings.Cut(value, "?")
+		}
+		found = append(found, extraction{Rule: "graphql-endpoint", Value: value, Start: loc[0], End: loc[0] + len(raw), Tags: []string{tag}, host: host})
+	}
+	for _, m := range endpointRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		start, end := m[2], m[3]
+		if start < 0 {
+			start, end = m[4], m[5]
+		}
+		path := trimURLPunctuation(fragment[start:end])
+		end = start + len(path)
+		route, _, _ := strings.Cut(path, "?")
+		tag, ok := graphqlRoute(route)
+		if !ok || strings.HasPrefix(route, "//") {
+			continue
+		}
+		if e.stripQuery {
+			path = route
+		}
+		found = append(found, extraction{Rule: "graphql-endpoint", Value: path, Start: start, End: end, Tags: []string{tag}})
+	}
+	for _, loc := range introspectionRegex.FindAllStringIndex(fragment, -1) {
+		value := strings.TrimRight(fragment[loc[0]:loc[1]], " \t\r\n{(")
+		found = append(found, extraction{
+			Rule:     "graphql-introspection",
+			Value:    value,
+			Start:    loc[0],
+			End:      loc[0] + len(value),
+			Severity: "medium",
+			Tags:     []string{"introspection"},
+		})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
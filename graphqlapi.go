@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// apiModes são as APIs do GitHub aceitas em -api.
+var apiModes = []string{"rest", "graphql"}
+
+// graphqlURL devolve o endereço da API GraphQL: no Enterprise Server, ela
+// fica em /api/graphql, fora de /api/v3.
+func (c *githubClient) graphqlURL() string {
+	if c.apiURL == "" || c.apiURL == githubAPIURL {
+		return githubAPIURL + "/graphql"
+	}
+	return strings.TrimSuffix(c.apiURL, enterpriseAPIPath) + "/api/graphql"
+}
+
+// repoMetadata são os dados do repositório que -api graphql acrescenta aos
+// resultados.
+type repoMetadata struct {
+	language string
+	stars    int
+}
+
+// graphqlSearcher implementa -api graphql. A API GraphQL do GitHub não tem
+// busca de código, então cada página ainda vem da busca REST; os dados dos
+// repositórios da página (linguagem, estrelas e, com -owners, o CODEOWNERS)
+// vêm numa única consulta GraphQL, em vez de uma requisição por repositório e
+// por local do CODEOWNERS.
+type graphqlSearcher struct {
+	client *githubClient
+	// owners, com -owners, recebe o CODEOWNERS de cada repositório já lido.
+	owners *ownerResolver
+	repos  map[string]repoMetadata
+}
+
+func newGraphQLSearcher(client *githubClient, owners *ownerResolver) *graphqlSearcher {
+	return &graphqlSearcher{client: client, owners: owners, repos: make(map[string]repoMetadata)}
+}
+
+func (g *graphqlSearcher) searchCode(query string, page, perPage int) (*CodeSearchResult, error) {
+	result, err := g.client.searchCode(query, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, item := range result.Items {
+		repo := item.Repository.FullName
+		if _, ok := g.repos[repo]; !ok && !contains(pending, repo) {
+			pending = append(pending, repo)
+		}
+	}
+	if len(pending) > 0 {
+		if err := g.lookup(pending); err != nil {
+			return nil, fmt.Errorf("consultar repositórios (-api graphql): %w", err)
+		}
+	}
+	for i := range result.Items {
+		meta := g.repos[result.Items[i].Repository.FullName]
+		result.Items[i].Repository.Language = meta.language
+		result.Items[i].Repository.Stars = meta.stars
+	}
+	// Regrava a página com os dados dos repositórios, para o -replay tê-los.
+	if err := g.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lookup consulta os repositórios numa só requisição, com um alias por
+// repositório (r0, r1...) e, com -owners, um por local do CODEOWNERS.
+func (g *graphqlSearcher) lookup(repos []string) error {
+	var params, fields []string
+	variables := make(map[string]any)
+	for i, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		variables[fmt.Sprintf("o%d", i)] = owner
+		variables[fmt.Sprintf("n%d", i)] = name
+		params = append(params, fmt.Sprintf("$o%d: String!, $n%d: String!", i, i))
+		field := fmt.Sprintf("r%d: repository(owner: $o%d, name: $n%d) { primaryLanguage { name } stargazerCount", i, i, i)
+		if g.owners != nil {
+			for j, p := range codeownersPaths {
+				field += fmt.Sprintf(" codeowners%d: object(expression: %q) { ... on Blob { text } }", j, "HEAD:"+p)
+			}
+		}
+		fields = append(fields, field+" }")
+	}
+	query := fmt.Sprintf("query (%s) {\n%s\n}", strings.Join(params, ", "), strings.Join(fields, "\n"))
+
+	token := g.client.tokens.pick()
+	if err := token.fresh(); err != nil {
+		return fmt.Errorf("renovar token da GitHub App: %w", err)
+	}
+	header := http.Header{}
+	header.Set("Authorization", "bearer "+token.value)
+	var data map[string]map[string]json.RawMessage
+	errs, err := g.client.graphQL(g.client.graphqlURL(), header, query, variables, &data)
+	if err != nil {
+		return err
+	}
+	// Um repositório renomeado, apagado ou sem acesso falha sozinho (NOT_FOUND
+	// no alias dele): fica sem linguagem e estrelas, e a busca continua.
+	failed := make(map[string]bool)
+	for _, e := range errs {
+		alias := ""
+		if len(e.Path) > 0 {
+			alias, _ = e.Path[0].(string)
+		}
+		var i int
+		if _, err := fmt.Sscanf(alias, "r%d", &i); err == nil && i >= 0 && i < len(repos) {
+			failed[alias] = true
+			log.Printf("Aviso: dados do repositório %s indisponíveis (-api graphql): %s", repos[i], e.Message)
+			continue
+		}
+		log.Printf("Aviso: erro na consulta GraphQL (-api graphql): %s", e.Message)
+	}
+
+	for i, repo := range repos {
+		alias := fmt.Sprintf("r%d", i)
+		fields := data[alias]
+		var meta repoMetadata
+		var language struct {
+			Name string `json:"name"`
+		}
+		if err := decodeField(fields["primaryLanguage"], &language); err != nil {
+			return err
+		}
+		if err := decodeField(fields["stargazerCount"], &meta.stars); err != nil {
+			return err
+		}
+		meta.language = language.Name
+		g.repos[repo] = meta
+		// Sem os dados, o -owners lê o CODEOWNERS pela API REST, como sem -api.
+		if g.owners == nil || failed[alias] || fields == nil {
+			continue
+		}
+		// Mesma ordem de codeownersPaths: vale o primeiro que existir.
+		var rules []codeownersRule
+		for j := range codeownersPaths {
+			var blob struct {
+				Text *string `json:"text"`
+			}
+			if err := decodeField(fields[fmt.Sprintf("codeowners%d", j)], &blob); err != nil {
+				return err
+			}
+			if blob.Text != nil {
+				rules = parseCodeowners(*blob.Text)
+				break
+			}
+		}
+		g.owners.codeowners[repo] = rules
+	}
+	return nil
+}
+
+// decodeField decodifica um campo da resposta GraphQL. Campo ausente (alias que
+// falhou) fica com o valor zero; null também, pelo próprio json.Unmarshal.
+func decodeField(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("decodificar JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeField(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     json.RawMessage
+		want    int
+		wantErr bool
+	}{
+		{"ausente", nil, 0, false},
+		{"null", json.RawMessage("null"), 0, false},
+		{"número", json.RawMessage("42"), 42, false},
+		{"tipo errado", json.RawMessage(`"42"`), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			err := decodeField(tt.raw, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeField(%s) erro = %v; quer erro %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeField(%s) = %d; quer %d", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// grepAppURL é a raiz do grep.app, que indexa repositórios públicos do GitHub.
+const grepAppURL = "https://grep.app"
+
+// O grep.app devolve 10 resultados por página, em até 100 páginas.
+const (
+	grepAppPerPage  = 10
+	grepAppMaxPages = 100
+)
+
+// grepAppSearcher usa a API do grep.app (-provider grepapp), que não exige
+// autenticação: serve para buscas rápidas sem token do GitHub. Com
+// -pattern-type regexp, a query é uma regex; com -org, a busca fica restrita
+// aos repositórios da organização.
+type grepAppSearcher struct {
+	regexp bool
+	org    string
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+}
+
+// githubQualifiers são os qualificadores da busca do GitHub que o grep.app
+// não entende: ele os procura como texto.
+var githubQualifiers = []string{"org:", "user:", "repo:", "filename:", "extension:", "path:", "language:", "in:", "size:", "fork:"}
+
+// queryQualifiers devolve os termos da query com qualificadores do GitHub.
+func queryQualifiers(query string) []string {
+	var found []string
+	for _, term := range strings.Fields(query) {
+		if hasAnyPrefix(strings.ToLower(strings.TrimPrefix(term, "-")), githubQualifiers) {
+			found = append(found, term)
+		}
+	}
+	return found
+}
+
+// grepAppSearchResponse é a parte usada da resposta da busca. O trecho vem
+// como uma tabela HTML com uma linha por linha do arquivo.
+type grepAppSearchResponse struct {
+	Hits struct {
+		Total int `json:"total"`
+		Hits  []struct {
+			Repo struct {
+				Raw string `json:"raw"`
+			} `json:"repo"`
+			Branch struct {
+				Raw string `json:"raw"`
+			} `json:"branch"`
+			Path struct {
+				Raw string `json:"raw"`
+			} `json:"path"`
+			Content struct {
+				Snippet string `json:"snippet"`
+			} `json:"content"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// grepAppLines extrai o texto das linhas do trecho, sem o número da linha e
+// as marcações do realce.
+func grepAppLines(snippet string) ([]string, error) {
+	doc, err := html.Parse(strings.NewReader(snippet))
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, pre := range findElements(doc, func(n *html.Node) bool { return n.Data == "pre" }) {
+		lines = append(lines, strings.TrimRight(textContent(pre), "\r\n"))
+	}
+	return lines, nil
+}
+
+func (g *grepAppSearcher) searchCode(query string, page, _ int) (*CodeSearchResult, error) {
+	params := url.Values{"q": {query}, "page": {strconv.Itoa(page)}}
+	if g.regexp {
+		params.Set("regexp", "true")
+	}
+	if g.org != "" {
+		params.Set("f.repo.pattern", g.org+"/")
+	}
+	req, err := http.NewRequest("GET", grepAppURL+"/api/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	var resp grepAppSearchResponse
+	if _, err := g.client.fetchJSON(req, &resp); err != nil {
+		return nil, err
+	}
+
+	// Converte para o formato da API do GitHub, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{TotalCount: resp.Hits.Total}
+	for _, h := range resp.Hits.Hits {
+		lines, err := grepAppLines(h.Content.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("ler trecho de %s/%s: %w", h.Repo.Raw, h.Path.Raw, err)
+		}
+		item := CodeSearchItem{
+			Path:    h.Path.Raw,
+			HTMLURL: fmt.Sprintf("%s/%s/blob/%s/%s", githubWebURL, h.Repo.Raw, h.Branch.Raw, h.Path.Raw),
+		}
+		item.Repository.FullName = h.Repo.Raw
+		item.TextMatches = []TextMatch{{Fragment: strings.Join(lines, "\n")}}
+		result.Items = append(result.Items, item)
+	}
+	result.last = page*grepAppPerPage >= result.TotalCount || page >= grepAppMaxPages
+
+	if err := g.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
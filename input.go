@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// binarySniffLen é quanto do início de um arquivo é olhado para decidir se ele
+// é binário (contém um byte nulo), como fazem grep e git.
+const binarySniffLen = 8000
+
+// readInputs entrega o conteúdo de cada entrada local para fn. Diretórios são
+// percorridos recursivamente, pulando arquivos binários; sem entradas, lê a
+// entrada padrão com o nome "stdin".
+func readInputs(paths []string, fn func(name, content string)) error {
+	if len(paths) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		fn("stdin", string(data))
+		return nil
+	}
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// Metadados de controle de versão só geram ruído.
+				if path != root && d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) >= 0 {
+				return nil
+			}
+			fn(path, string(data))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
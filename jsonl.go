@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// jsonlWriter grava um resultado JSON por linha.
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	enc := json.NewEncoder(w)
+	// Trechos de código trazem muitos <, > e &; escapá-los só atrapalha a leitura.
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{enc: enc}
+}
+
+func (j *jsonlWriter) write(v any) error {
+	return j.enc.Encode(v)
+}
+
+// isJSONLPath indica se o arquivo de saída deve ser gravado em JSONL,
+// considerando a extensão antes de um eventual .gz.
+func isJSONLPath(path string) bool {
+	path = strings.TrimSuffix(strings.ToLower(path), ".gz")
+	return strings.HasSuffix(path, ".jsonl")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// jwtRegex encontra tokens JWT: cabeçalho e payload em base64url começando
+// por eyJ ({"), seguidos da assinatura (vazia com alg none).
+var jwtRegex = regexp.MustCompile(`\beyJ[0-9A-Za-z_\-]+\.eyJ[0-9A-Za-z_\-]+\.[0-9A-Za-z_\-]*`)
+
+// jwtFields são os campos que o modo jwt acrescenta a cada resultado, aceitos
+// em -fields e disponíveis em groups no JSONL e em .Groups no -format.
+var jwtFields = []string{"alg", "iss", "sub", "aud", "exp"}
+
+// decodeJWTPart decodifica um segmento base64url do token como objeto JSON.
+func decodeJWTPart(part string) (map[string]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// claimString converte uma claim para texto; aud pode ser uma lista.
+func claimString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = claimString(item)
+		}
+		return strings.Join(values, ",")
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprint(v)
+}
+
+// findJWTs extrai tokens JWT cujo cabeçalho e payload sejam JSON válidos e
+// expõe as claims principais. Tokens expirados ficam com severidade low e a
+// tag expired; os demais, high, já que ainda podem ser usados. Tokens com
+// alg none, que dispensam assinatura, recebem a tag alg-none.
+func findJWTs(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, loc := range jwtRegex.FindAllStringIndex(fragment, -1) {
+		token := fragment[loc[0]:loc[1]]
+		parts := strings.Split(token, ".")
+		header, err := decodeJWTPart(parts[0])
+		if err != nil {
+			continue
+		}
+		claims, err := decodeJWTPart(parts[1])
+		if err != nil {
+			continue
+		}
+		x := extraction{
+			Value:    token,
+			Start:    loc[0],
+			End:      loc[1],
+			Severity: "high",
+			Groups: map[string]string{
+				"alg": claimString(header["alg"]),
+				"iss": claimString(claims["iss"]),
+				"sub": claimString(claims["sub"]),
+				"aud": claimString(claims["aud"]),
+			},
+		}
+		if exp, ok := claims["exp"].(float64); ok {
+			expiry := time.Unix(int64(exp), 0).UTC()
+			x.Groups["exp"] = expiry.Format(time.RFC3339)
+			if expiry.Before(time.Now()) {
+				x.Severity = "low"
+				x.Tags = append(x.Tags, "expired")
+			}
+		} else {
+			x.Tags = append(x.Tags, "no-expiry")
+		}
+		if strings.EqualFold(x.Groups["alg"], "none") {
+			x.Tags = append(x.Tags, "alg-none")
+		}
+		for name, v := range x.Groups {
+			if v == "" {
+				delete(x.Groups, name)
+			}
+		}
+		found = append(found, x)
+	}
+	return found
+}
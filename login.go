@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// loginScopes são os escopos pedidos no login: repo permite buscar também o
+// código privado a que a conta tem acesso.
+const loginScopes = "repo"
+
+// credentials é o arquivo onde o login guarda o token obtido. Host é o
+// servidor da API em que o token vale (api.github.com ou o do Enterprise
+// Server de -github-url); arquivos antigos, sem ele, são do github.com.
+type credentials struct {
+	Token string    `json:"token"`
+	User  string    `json:"user,omitempty"`
+	Host  string    `json:"host,omitempty"`
+	Saved time.Time `json:"saved"`
+}
+
+// credentialsPath fica ao lado do arquivo de configuração padrão.
+func credentialsPath() string {
+	return filepath.Join(filepath.Dir(defaultConfigPath()), "credentials.json")
+}
+
+// loadCredential devolve o token guardado pelo login para o servidor da API,
+// ou "" se não houver; o token de outro servidor nunca é enviado a este.
+func loadCredential(host string) string {
+	data, err := os.ReadFile(credentialsPath())
+	if err != nil {
+		return ""
+	}
+	var c credentials
+	if json.Unmarshal(data, &c) != nil {
+		return ""
+	}
+	if c.Host == "" {
+		c.Host = apiHost(githubAPIURL)
+	}
+	if c.Host != host {
+		return ""
+	}
+	return c.Token
+}
+
+// saveCredential grava o token só com permissão de leitura para o dono.
+func saveCredential(c credentials) error {
+	path := credentialsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// postForm envia um formulário para o fluxo OAuth e decodifica a resposta JSON.
+func postForm(c *githubClient, endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequest("POST", c.web()+endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d em %s: %s", resp.StatusCode, endpoint, string(body))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// deviceLogin faz o fluxo de dispositivo do OAuth: mostra o código para o
+// usuário autorizar no navegador e consulta o GitHub até o token sair.
+func deviceLogin(c *githubClient, clientID string, w io.Writer) (string, error) {
+	var code struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+	}
+	err := postForm(c, "/login/device/code", url.Values{"client_id": {clientID}, "scope": {loginScopes}}, &code)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(w, "Abra %s e informe o código: %s\n", code.VerificationURI, code.UserCode)
+
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+		var result struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		err := postForm(c, "/login/oauth/access_token", url.Values{
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &result)
+		if err != nil {
+			return "", err
+		}
+		switch result.Error {
+		case "":
+			return result.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			// O GitHub pede para espaçar mais as consultas.
+			interval += 5 * time.Second
+		default:
+			return "", fmt.Errorf("%s: %s", result.Error, result.Description)
+		}
+	}
+	return "", errors.New("o código expirou antes da autorização; rode o login de novo")
+}
+
+// currentUser devolve o login da conta dona do token, ou "" se a consulta falhar.
+func currentUser(c *githubClient) string {
+	status, _, body, err := c.get(c.api("/user"), "application/vnd.github+json")
+	if err != nil || status != http.StatusOK {
+		return ""
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	json.Unmarshal(body, &user)
+	return user.Login
+}
@@ -1,206 +1,882 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
-	"net"
-	"net/http"
-	"net/url"
 	"os"
-	"regexp"
 	"strings"
+	"text/template"
 	"time"
 )
 
 // CodeSearchResult estrutura a resposta da API de busca de código do GitHub.
 type CodeSearchResult struct {
-	TotalCount int `json:"total_count"`
-	Items      []struct {
-		HTMLURL     string `json:"html_url"`
-		TextMatches []struct {
-			Fragment string `json:"fragment"`
-		} `json:"text_matches"`
-	} `json:"items"`
+	TotalCount int              `json:"total_count"`
+	Items      []CodeSearchItem `json:"items"`
+
+	// last indica que não há mais páginas depois desta.
+	last bool
 }
 
-func extractDomain(rawURL string) string {
-	// Se a URL começar com //, adiciona "http:" para possibilitar o parse.
-	if strings.HasPrefix(rawURL, "//") {
-		rawURL = "http:" + rawURL
-	}
-	u, err := url.Parse(rawURL)
-	if err != nil {
-		return ""
-	}
-	// Caso o host contenha a porta, separe-a.
-	host, _, err := net.SplitHostPort(u.Host)
-	if err != nil {
-		// Se der erro, pode ser que não haja porta.
-		host = u.Host
-	}
-	return host
+// CodeSearchItem é um arquivo encontrado, com os trechos que casaram.
+type CodeSearchItem struct {
+	Path       string `json:"path"`
+	HTMLURL    string `json:"html_url"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		// Language e Stars só vêm com -api graphql.
+		Language string `json:"language,omitempty"`
+		Stars    int    `json:"stargazers_count,omitempty"`
+	} `json:"repository"`
+	TextMatches []TextMatch `json:"text_matches"`
 }
 
+// TextMatch é um trecho do arquivo que casou com a busca.
+type TextMatch struct {
+	Fragment string `json:"fragment"`
+}
+
+// subcommands são os subcomandos aceitos como primeiro argumento.
+var subcommands = []string{"extract", "token-check", "login"}
+
 func main() {
+	// Subcomandos: "gfinder extract" roda só a extração e os filtros sobre
+	// arquivos locais ou a entrada padrão, sem chamadas ao GitHub; "gfinder
+	// token-check" valida os tokens antes de uma busca; "gfinder login" obtém e
+	// guarda um token pelo fluxo de dispositivo do OAuth. As flags são as mesmas.
+	var subcommand string
+	if len(os.Args) > 1 && contains(subcommands, os.Args[1]) {
+		subcommand = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	offline := subcommand == "extract"
+
 	// Flags de linha de comando:
 	// -q: query simples para a API do GitHub.
+	// -q2: query na sintaxe nova da busca de código (/regex/, path:, content:), feita pelo site com GITHUB_SESSION.
+	// -provider / -gitlab-url / -base-url: serviço de busca de código (github, gitlab com GITLAB_TOKEN, bitbucket com BITBUCKET_TOKEN ou gitea) e a instância do GitLab ou do Gitea/Forgejo.
 	// -r: regex para filtrar os resultados.
-	// -m: modo de extração: "urls" ou "domains". Quando definido, a extração será feita com uma regex interna e filtrada com -r.
-	// -d: delay entre requisições.
+	// -m: modos de extração, separados por vírgula (urls, domains, rootdomains, emails, ips, subdomains, s3, buckets, secrets, jwt, privatekeys, creds, endpoints, params, env, webhooks, hostports, wallets, internal, docker, packages, graphql, entropy, firebase, cidrs, patterns). Quando definido, a extração será feita com uma regex interna e filtrada com -r.
+	// -d: delay fixo entre requisições (sem ele, o intervalo se ajusta aos cabeçalhos X-RateLimit).
 	// -s: silent, apenas exibe os resultados extraídos sem a URL do arquivo, garantindo resultados únicos.
+	// -unique: remove duplicados em qualquer formato de saída.
+	// -dedupe-by: chave usada na deduplicação; quando informada, ativa a deduplicação.
+	// -strip-query: remove a query string das URLs extraídas antes da deduplicação.
+	// -max-per-file / -max-per-repo: limitam quantos resultados um arquivo ou repositório pode gerar.
+	// -fields: escolhe as colunas da saída em texto (separadas por tab).
+	// -format: template (text/template) que monta a linha de cada resultado, ex: '{{.Repo}} {{.Match}}'.
+	// -print0: separa os resultados com NUL, para xargs -0.
+	// -o: formato da saída (json) ou arquivo de saída, com o formato pela extensão (compactado com gzip se terminar em .gz).
+	// -output / -append: arquivo de saída gravado de forma atômica, opcionalmente acumulando execuções.
+	// -rotate-daily / -rotate-size / -keep: rotação do arquivo de -o em execuções longas.
+	// -report: gera um relatório HTML autônomo com os resultados agrupados por repositório.
+	// -tee: grava todos os resultados em JSONL num arquivo, mantendo a saída legível no terminal.
+	// -i: compila a regex (e as regexes internas, quando aplicável) sem diferenciar maiúsculas.
+	// -engine: motor da regex de filtro: "re2" (padrão do Go) ou "pcre" (lookarounds e backreferences).
+	// -og / -print-group: exibe apenas a captura de um grupo (número ou nome) da regex de filtro.
+	// -rf: arquivo com padrões de filtro, um por linha (combinados com -r).
+	// -patterns: pacotes de extratores em YAML (regex, pós-filtro, rótulo e severidade), usados no modo patterns.
+	// -F: trata os padrões como strings literais, casadas com Aho-Corasick.
+	// -multiline / -dotall / -join-lines: permitem padrões que atravessam quebras de linha.
+	// -C: exibe linhas do trecho antes e depois de cada ocorrência.
+	// -v-match: inverte o filtro, emitindo os valores (ou trechos, sem modo) que não casam com -r.
+	// -idn: forma em que domínios internacionalizados são emitidos ("ascii" ou "unicode").
+	// -scope / -t / -scope-apex: restringem URLs e domínios extraídos ao escopo.
+	// -no-unescape: desativa a decodificação de URLs escapadas antes da extração.
+	// -with-ports: no modo domains, emite host:porta quando a URL traz uma porta.
+	// -public-ips: nos modos ips e cidrs, descarta endereços privados e reservados.
+	// -check-registry: no modo packages, marca os pacotes que não existem no registro público.
+	// -entropy-threshold / -entropy-min-len: no modo entropy, entropia mínima (bits por caractere) e tamanho mínimo das strings.
+	// -unmask: no modo creds, exibe as senhas, que por padrão saem como ****.
+	// -strict-domains: descarta hosts com sintaxe inválida ou TLD inexistente.
+	// -refang: rearma indicadores desarmados (hxxp://, example[.]com) antes da extração.
+	// -export: grava um documento completo para outra ferramenta (misp, stix, burp, zap, nuclei, remediation) em vez da saída em linhas.
+	// -of: imprime só os hosts no formato de outra ferramenta de recon (subfinder, subfinder-json, amass).
+	// -sources: com -of, anota cada host com os repositórios onde foi encontrado.
+	// -nuclei-templates: com -export nuclei, gera um template por endpoint encontrado no diretório informado.
+	// -record: grava as respostas brutas da API num diretório de captura.
+	// -replay: reprocessa um diretório de captura no lugar da API, sem gastar limite de requisições.
+	// -audit-log: registra cada requisição à API (URL, status, limites, duração) em JSONL.
+	// -org: restringe a busca aos repositórios de uma organização (varredura defensiva).
+	// -owners: atribui cada resultado a um responsável (CODEOWNERS ou autor do último commit).
+	// -client / -config: aplicam a configuração de um cliente (escopo, token, organização e diretório de saída).
+	// -tokens: vários tokens do GitHub (separados por vírgula ou em arquivo), revezados a cada requisição.
+	// -retries / -retry-wait: novas tentativas, com espera crescente, após falhas de rede ou 5xx.
+	// -app-id / -app-key / -app-installation: autenticam como GitHub App, com tokens de instalação renovados automaticamente.
+	// -use-gh: sem outro token, reaproveita as credenciais do gh CLI (gh auth token, hosts.yml ou chaveiro).
+	// -pool-stats: ao final, exibe o uso e o limite restante de cada token.
+	// -oauth-client-id: no subcomando login, client ID da OAuth App usada no fluxo de dispositivo.
+	// -input: no subcomando extract, arquivos ou diretórios lidos no lugar da entrada padrão.
+	// -top: agrega os valores extraídos e exibe os N mais frequentes com a contagem.
+	// -sort-output: ordena a saída final (os resultados ficam em memória até o fim da busca).
 	apiQuery := flag.String("q", "", "Query de busca para a API do GitHub (ex: mercadolivre)")
+	apiMode := flag.String("api", "rest", "API do GitHub: 'rest' ou 'graphql' (a busca continua na REST; linguagem, estrelas e o CODEOWNERS de -owners vêm numa consulta GraphQL por página)")
+	webQuery := flag.String("q2", "", "Query na sintaxe nova da busca de código, com /regex/ e path: (ex: 'path:*.env /AKIA[0-9A-Z]{16}/'); exige GITHUB_SESSION")
+	provider := flag.String("provider", "github", "Serviço de busca de código: "+strings.Join(providers, ", ")+" (o GitLab usa GITLAB_TOKEN; o Bitbucket, BITBUCKET_TOKEN e o workspace em -org; o Gitea/Forgejo, -base-url; o Sourcegraph, SRC_ACCESS_TOKEN, opcional; o grep.app e o searchcode não exigem autenticação; com ',searchcode', ex: github,searchcode, o searchcode.com roda depois do provedor, sem repetir arquivos)")
+	githubBase := flag.String("github-url", githubAPIURL, "Raiz da API de um GitHub Enterprise Server (ex: https://ghe.acme.com/api/v3)")
+	gitlabBase := flag.String("gitlab-url", gitlabURL, "Com -provider gitlab, raiz da instância (ex: https://gitlab.acme.com)")
+	baseURL := flag.String("base-url", "", "Com -provider gitea ou sourcegraph, raiz do servidor (ex: https://git.internal; no Sourcegraph, o padrão é "+sourcegraphURL+")")
+	patternType := flag.String("pattern-type", "literal", "Com -provider sourcegraph ou grepapp, tipo da busca feita no servidor: "+strings.Join(sourcegraphPatternTypes, ", "))
 	regexStr := flag.String("r", "", "Regex para filtrar os resultados localmente (ex: mercadolivre)")
-	mode := flag.String("m", "", "Modo de extração: 'urls' ou 'domains' (opcional)")
-	delay := flag.Int("d", 2, "Delay em segundos entre requisições para evitar bloqueio")
+	mode := flag.String("m", "", "Modos de extração, separados por vírgula (ex: urls,emails,secrets): "+strings.Join(extractionModes, ", ")+" (opcional)")
+	delay := flag.Int("d", 0, "Delay fixo em segundos entre requisições; sem -d, o intervalo segue os cabeçalhos de limite da API")
 	silent := flag.Bool("s", false, "Silent: somente exibe os resultados extraídos (únicos), sem a URL do arquivo")
+	unique := flag.Bool("unique", false, "Remove resultados duplicados em qualquer formato de saída")
+	dedupeBy := flag.String("dedupe-by", "match", "Chave de deduplicação: 'match', 'match+file', 'match+repo' ou 'fingerprint' (implica -unique)")
+	sortOutput := flag.String("sort-output", "", "Ordena a saída final: 'alpha', 'count' ou 'repo' (opcional)")
+	stripQuery := flag.Bool("strip-query", false, "Remove a query string das URLs extraídas antes de filtrar e deduplicar")
+	maxPerFile := flag.Int("max-per-file", 0, "Máximo de resultados por arquivo (0 = sem limite)")
+	maxPerRepo := flag.Int("max-per-repo", 0, "Máximo de resultados por repositório (0 = sem limite)")
+	top := flag.Int("top", 0, "Exibe apenas os N valores mais frequentes, com a contagem de ocorrências (opcional)")
+	formatText := flag.String("format", "", "Template Go aplicado a cada resultado, ex: '{{.Repo}} {{.Match}}' (campos: Repo, Path, FileURL, Match, Mode, Rule, Query, Line, Start, End, Page, Severity, Tags, Owner, Language, Stars, Groups, Fragment, Context, Fingerprint)")
+	fieldList := flag.String("fields", "", "Campos exibidos, separados por vírgula: url, repo, path, match, rule, mode, query, line, start, end, severity, tags, owner, language, stars, page, fragment ou grupos nomeados da regex (opcional)")
+	print0 := flag.Bool("print0", false, "Separa os resultados com NUL em vez de quebra de linha, para uso com xargs -0")
+	outputFile := flag.String("output", "", "Arquivo de saída gravado de forma atômica (temporário + rename) ao final da execução")
+	appendOutput := flag.Bool("append", false, "Com -output, acrescenta os resultados ao conteúdo atual do arquivo em vez de substituí-lo")
+	outputPath := flag.String("o", "", "Formato da saída ("+formatNames()+") ou arquivo de saída, com o formato pela extensão e gzip se terminar em .gz (ex: results.json.gz)")
+	rotateDaily := flag.Bool("rotate-daily", false, "Rotaciona o arquivo de -o a cada dia (ex: results-2024-05-01.jsonl)")
+	rotateSize := flag.String("rotate-size", "", "Rotaciona o arquivo de -o ao atingir o tamanho (ex: 100MB)")
+	keep := flag.Int("keep", 0, "Quantidade de arquivos rotacionados mantidos (0 = todos)")
+	reportPath := flag.String("report", "", "Gera um relatório HTML com os resultados agrupados por repositório (ex: report.html)")
+	teePath := flag.String("tee", "", "Grava também todos os resultados em JSONL neste arquivo (ex: findings.jsonl)")
+	ignoreCase := flag.Bool("i", false, "Ignora maiúsculas/minúsculas na regex de filtro e nas regexes internas")
+	engine := flag.String("engine", "re2", "Motor da regex de filtro: 're2' ou 'pcre' (lookahead/lookbehind e backreferences)")
+	var printGroup string
+	flag.StringVar(&printGroup, "og", "", "Exibe apenas a captura deste grupo da regex de filtro, por número ou nome (ex: 1, host)")
+	flag.StringVar(&printGroup, "print-group", "", "Mesmo que -og")
+	patternsFile := flag.String("rf", "", "Arquivo com padrões de filtro, um por linha (combinados com -r)")
+	packPaths := flag.String("patterns", "", "Pacotes de extratores em YAML, separados por vírgula; ativam o modo patterns (ex: acme.yaml,cloud.yaml)")
+	fixedStrings := flag.Bool("F", false, "Trata -r/-rf como strings literais, sem metacaracteres de regex")
+	multiline := flag.Bool("multiline", false, "Faz ^ e $ casarem no início e no fim de cada linha do trecho")
+	dotall := flag.Bool("dotall", false, "Faz . casar também com quebras de linha (ex: blocos PEM)")
+	joinLines := flag.Bool("join-lines", false, "Junta as linhas do trecho (quebras viram espaços) antes de aplicar o filtro")
+	contextLines := flag.Int("C", 0, "Exibe N linhas do trecho antes e depois de cada ocorrência, com destaque")
+	invertMatch := flag.Bool("v-match", false, "Emite o que NÃO casa com -r: valores extraídos pelo modo ou, sem modo, trechos inteiros")
+	idnForm := flag.String("idn", "ascii", "Forma dos domínios internacionalizados: 'ascii' (xn--...) ou 'unicode'")
+	scopeFile := flag.String("scope", "", "Arquivo de escopo: um host ou curinga (*.example.com) por linha")
+	targets := flag.String("t", "", "Domínios alvo, separados por vírgula; equivalem a *.dominio no escopo")
+	scopeApex := flag.Bool("scope-apex", false, "Faz *.example.com casar também com example.com")
+	noUnescape := flag.Bool("no-unescape", false, "Não decodifica URLs escapadas (https:\\/\\/, %2F, &#x2F;) antes da extração")
+	checkRegistry := flag.Bool("check-registry", false, "No modo packages, consulta o registro público (npm, PyPI, RubyGems) e marca como unclaimed os pacotes inexistentes")
+	entropyThreshold := flag.Float64("entropy-threshold", 4.5, "No modo entropy, entropia de Shannon mínima em bits por caractere para base64 (0 a 6); hexadecimal usa o mesmo limite na proporção 4/6")
+	entropyMinLen := flag.Int("entropy-min-len", 20, "No modo entropy, tamanho mínimo das strings analisadas")
+	unmask := flag.Bool("unmask", false, "No modo creds, exibe as senhas mesmo fora do modo silencioso")
+	publicIPs := flag.Bool("public-ips", false, "Nos modos ips e cidrs, descarta endereços privados, de loopback, link-local, multicast e faixas reservadas")
+	withPorts := flag.Bool("with-ports", false, "No modo domains, emite host:porta quando a URL tem porta não padrão (ex: api.example.com:8443)")
+	strictDomains := flag.Bool("strict-domains", false, "Descarta hosts com sintaxe inválida ou TLD inexistente (ex: foo.prototype.js)")
+	refang := flag.Bool("refang", false, "Rearma indicadores desarmados antes da extração (hxxp://, example[.]com, 1.2.3[.]4)")
+	outputProfile := flag.String("of", "", "Perfil de saída compatível com outras ferramentas: 'subfinder', 'subfinder-json' ou 'amass'")
+	profileSources := flag.Bool("sources", false, "Com -of, anota cada host com os repositórios onde foi encontrado")
+	nucleiTemplates := flag.String("nuclei-templates", "", "Com -export nuclei, gera templates para reconferir os endpoints neste diretório")
+	exportFormat := flag.String("export", "", "Exporta os resultados para outra ferramenta: 'misp', 'stix', 'burp', 'zap', 'nuclei' ou 'remediation'")
+	recordDir := flag.String("record", "", "Grava as respostas brutas da API neste diretório, para reprocessar com -replay")
+	replayDir := flag.String("replay", "", "Reprocessa as respostas gravadas com -record neste diretório, sem chamar a API")
+	auditPath := flag.String("audit-log", "", "Registra cada requisição à API (URL, status, cabeçalhos de limite e duração) em JSONL neste arquivo")
+	org := flag.String("org", "", "Restringe a busca aos repositórios desta organização (ex: acme)")
+	resolveOwners := flag.Bool("owners", false, "Atribui cada resultado a um responsável pelo CODEOWNERS do repositório ou pelo autor do último commit no arquivo")
+	clientName := flag.String("client", "", "Usa a configuração deste cliente: escopo, token, organização e diretório de saída próprios")
+	configPath := flag.String("config", defaultConfigPath(), "Arquivo de configuração com os clientes de -client")
+	tokenList := flag.String("tokens", "", "Tokens do GitHub revezados a cada requisição: separados por vírgula ou arquivo com um por linha")
+	retries := flag.Int("retries", 3, "Novas tentativas após falhas transitórias (timeout, conexão reiniciada, 5xx)")
+	retryWait := flag.Duration("retry-wait", 2*time.Second, "Espera antes da primeira nova tentativa; dobra a cada tentativa, com variação aleatória")
+	appID := flag.String("app-id", "", "ID da GitHub App usada na autenticação (com -app-key)")
+	appKey := flag.String("app-key", "", "Arquivo PEM com a chave privada da GitHub App")
+	appInstallation := flag.String("app-installation", "", "ID da instalação da GitHub App (opcional se houver só uma)")
+	useGH := flag.Bool("use-gh", false, "Sem outro token, usa o do gh CLI (gh auth token, ~/.config/gh/hosts.yml ou o chaveiro do sistema)")
+	poolStats := flag.Bool("pool-stats", false, "Ao final, exibe na saída de erro as requisições e o limite restante de cada token")
+	oauthClientID := flag.String("oauth-client-id", os.Getenv("GFINDER_OAUTH_CLIENT_ID"), "No subcomando login, client ID da OAuth App com o fluxo de dispositivo habilitado (ou GFINDER_OAUTH_CLIENT_ID)")
+	inputPaths := flag.String("input", "", "No subcomando extract, arquivos ou diretórios a ler, separados por vírgula (padrão: entrada padrão)")
 	flag.Parse()
 
-	if *apiQuery == "" {
-		log.Fatal("Você deve fornecer uma query para a API com o parâmetro -q")
+	// -o com o nome de um formato grava na saída padrão nesse formato.
+	var outputFormat string
+	if outputFormats[*outputPath] != nil {
+		outputFormat, *outputPath = *outputPath, ""
+	}
+	// -output usa o mesmo caminho de -o, mas grava o arquivo de uma vez no fim.
+	atomicOutput := *outputFile != ""
+	if atomicOutput {
+		if *outputPath != "" {
+			log.Fatal("-output não pode ser usado com um arquivo em -o; em -o, informe só o formato")
+		}
+		*outputPath = *outputFile
 	}
-	if *regexStr == "" {
-		log.Fatal("Você deve fornecer uma regex para filtrar os resultados com o parâmetro -r")
+	if *appendOutput && !atomicOutput {
+		log.Fatal("-append exige -output")
 	}
 
-	// Se não estivermos usando modo, compilamos a regex para filtrar os trechos.
-	var re *regexp.Regexp
-	var err error
-	if *mode == "" {
-		re, err = regexp.Compile(*regexStr)
+	// -client: os valores do cliente só preenchem o que não veio nas flags, e
+	// caminhos relativos passam a ficar no diretório de saída dele.
+	var tenant *clientConfig
+	if *clientName != "" {
+		cfg, err := loadConfig(*configPath)
 		if err != nil {
-			log.Fatalf("Erro ao compilar a regex: %v", err)
+			log.Fatalf("Erro ao ler configuração: %v", err)
 		}
-	} else {
-		// Se estiver usando modo ("urls" ou "domains"), compilamos a regex de filtro que será aplicada
-		// sobre cada URL ou domínio extraído.
-		re, err = regexp.Compile(*regexStr)
+		if tenant, err = cfg.client(*clientName); err != nil {
+			log.Fatalf("Erro em -client: %v", err)
+		}
+		dir, err := tenant.outputDir()
 		if err != nil {
-			log.Fatalf("Erro ao compilar a regex de filtro: %v", err)
+			log.Fatalf("Erro ao criar diretório do cliente: %v", err)
+		}
+		for _, p := range []*string{outputPath, reportPath, teePath, recordDir, replayDir, auditPath, nucleiTemplates} {
+			*p = inDir(dir, *p)
 		}
-		if *mode != "urls" && *mode != "domains" {
-			log.Fatal("O modo (-m) deve ser 'urls' ou 'domains'")
+		if *org == "" && !offline && *replayDir == "" {
+			*org = tenant.Org
 		}
 	}
 
-	// Regex interna para extração de URLs.
-	urlRegex := regexp.MustCompile(`((https?:\/\/|\/\/)[^\s"'<>]+)`)
+	// Com -github-url, as buscas, o login e o token-check vão para o
+	// Enterprise Server; apiBase vazio é o github.com.
+	apiBase, err := parseGitHubURL(*githubBase)
+	if err != nil {
+		log.Fatalf("Erro em -github-url: %v", err)
+	}
+	if apiBase == githubAPIURL {
+		apiBase = ""
+	}
 
-	// Obtém a chave do GitHub da variável de ambiente, se disponível.
-	githubKey := os.Getenv("GITHUB_KEY")
+	if subcommand == "login" {
+		if *oauthClientID == "" {
+			log.Fatal("O login exige o client ID de uma OAuth App com -oauth-client-id (ou GFINDER_OAUTH_CLIENT_ID)")
+		}
+		client := &githubClient{tokens: newTokenPool(nil), apiURL: apiBase, retries: *retries, retryWait: *retryWait, quiet: true}
+		token, err := deviceLogin(client, *oauthClientID, os.Stderr)
+		if err != nil {
+			log.Fatalf("Erro no login: %v", err)
+		}
+		cred := credentials{Token: token, Host: apiHost(client.api("")), Saved: time.Now().UTC()}
+		client.tokens = newTokenPool([]string{token})
+		cred.User = currentUser(client)
+		if err := saveCredential(cred); err != nil {
+			log.Fatalf("Erro ao guardar o token: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Login concluído como %s; token guardado em %s\n", cred.User, credentialsPath())
+		return
+	}
 
-	perPage := 100 // Máximo permitido pela API.
-	page := 1
+	if subcommand == "token-check" {
+		client := &githubClient{apiURL: apiBase, retries: *retries, retryWait: *retryWait, quiet: true}
+		tokens, err := resolveTokens(*tokenList, tenant, *useGH, client)
+		if err != nil {
+			log.Fatalf("Erro ao ler -tokens: %v", err)
+		}
+		ok, err := checkTokens(client, tokens, os.Stdout)
+		if err != nil {
+			log.Fatalf("Erro ao verificar os tokens: %v", err)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Mapa para garantir resultados únicos quando o modo silent estiver ativado.
-	uniqueResults := make(map[string]bool)
+	// Com ",searchcode" (ex: github,searchcode), o searchcode.com roda depois
+	// do provedor principal, sem repetir os arquivos que ele já trouxe.
+	withSearchcode := false
+	if first, second, ok := strings.Cut(*provider, ","); ok {
+		if second != "searchcode" || first == "searchcode" {
+			log.Fatal("Só o searchcode pode ser combinado com outro provedor, depois do principal (ex: -provider github,searchcode)")
+		}
+		*provider, withSearchcode = first, true
+	}
+	if !contains(providers, *provider) {
+		log.Fatalf("O provedor (-provider) deve ser um de: %s", strings.Join(providers, ", "))
+	}
+	// Busca pelo site, tokens, GitHub App e -owners são recursos do GitHub.
+	if *provider != "github" && (*webQuery != "" || *tokenList != "" || *appID != "" || *useGH || *poolStats || *resolveOwners || apiBase != "") {
+		log.Fatal("-q2, -tokens, -app-id, -use-gh, -pool-stats, -owners e -github-url só se aplicam ao provedor github")
+	}
+	if !contains(apiModes, *apiMode) {
+		log.Fatalf("A API (-api) deve ser uma de: %s", strings.Join(apiModes, ", "))
+	}
+	if *apiMode == "graphql" && (*provider != "github" || *webQuery != "") {
+		log.Fatal("-api graphql só se aplica à busca da API do GitHub (-provider github, com -q)")
+	}
+	if *webQuery != "" && apiBase != "" {
+		log.Fatal("-q2 usa a busca do site do github.com e não funciona com -github-url; use -q")
+	}
+	if *provider == "gitlab" && !offline && *replayDir == "" && os.Getenv("GITLAB_TOKEN") == "" {
+		log.Fatal("A busca no GitLab exige um token de acesso na variável GITLAB_TOKEN")
+	}
+	if *provider == "gitea" && !offline && *replayDir == "" && *baseURL == "" {
+		log.Fatal("A busca no Gitea/Forgejo exige a raiz do servidor em -base-url")
+	}
+	if *baseURL != "" && *provider != "gitea" && *provider != "sourcegraph" {
+		log.Fatal("-base-url só se aplica aos provedores gitea e sourcegraph")
+	}
+	if !contains(sourcegraphPatternTypes, *patternType) {
+		log.Fatalf("O tipo de busca (-pattern-type) deve ser um de: %s", strings.Join(sourcegraphPatternTypes, ", "))
+	}
+	if *patternType != "literal" && *provider != "sourcegraph" && *provider != "grepapp" {
+		log.Fatal("-pattern-type só se aplica aos provedores sourcegraph e grepapp")
+	}
+	if *patternType == "structural" && *provider == "grepapp" {
+		log.Fatal("O grep.app não faz busca estrutural; use -pattern-type literal ou regexp")
+	}
+	if *provider == "bitbucket" && !offline && *replayDir == "" {
+		if os.Getenv("BITBUCKET_TOKEN") == "" {
+			log.Fatal("A busca no Bitbucket exige um access token (ou app password, com BITBUCKET_USERNAME) na variável BITBUCKET_TOKEN")
+		}
+		if *org == "" {
+			log.Fatal("A busca no Bitbucket é feita por workspace; informe-o em -org")
+		}
+	}
+	if *apiQuery != "" && *webQuery != "" {
+		log.Fatal("Use -q ou -q2, não os dois")
+	}
+	if *webQuery != "" {
+		if os.Getenv("GITHUB_SESSION") == "" {
+			log.Fatal("-q2 exige o cookie user_session de uma sessão do GitHub na variável GITHUB_SESSION")
+		}
+		*apiQuery = *webQuery
+	}
+	if offline && *apiQuery != "" {
+		log.Fatal("O subcomando extract não faz buscas; remova o parâmetro -q")
+	}
+	if !offline && *apiQuery == "" && *replayDir == "" {
+		log.Fatal("Você deve fornecer uma query para a API com o parâmetro -q (ou -q2)")
+	}
+	if *replayDir != "" && (offline || *recordDir != "" || *org != "") {
+		log.Fatal("-replay não pode ser usado com -record, -org nem com o subcomando extract")
+	}
+	if offline && (*recordDir != "" || *org != "" || *resolveOwners) {
+		log.Fatal("-record, -org e -owners não se aplicam ao subcomando extract")
+	}
+	if *exportFormat == "remediation" && !*resolveOwners {
+		log.Fatal("-export remediation exige -owners")
+	}
+	if !offline && (*inputPaths != "" || flag.NArg() > 0) {
+		log.Fatal("Arquivos de entrada (-input) só se aplicam ao subcomando extract")
+	}
+	if *regexStr == "" && *patternsFile == "" {
+		log.Fatal("Você deve fornecer uma regex para filtrar os resultados com o parâmetro -r (ou um arquivo de padrões com -rf)")
+	}
+	if !dedupeKeys[*dedupeBy] {
+		log.Fatal("A chave de deduplicação (-dedupe-by) deve ser 'match', 'match+file', 'match+repo' ou 'fingerprint'")
+	}
+	// Escolher uma chave de deduplicação só faz sentido com a deduplicação ativa.
+	fixedDelay := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "dedupe-by":
+			*unique = true
+		case "d":
+			fixedDelay = true
+		}
+	})
+	if *top < 0 {
+		log.Fatal("O valor de -top deve ser positivo")
+	}
+	if (*appID == "") != (*appKey == "") || (*appInstallation != "" && *appID == "") {
+		log.Fatal("A autenticação como GitHub App exige -app-id e -app-key")
+	}
+	if *appID != "" && *tokenList != "" {
+		log.Fatal("Use -tokens ou -app-id, não os dois")
+	}
+	if *retries < 0 || *retryWait < 0 {
+		log.Fatal("Os valores de -retries e -retry-wait devem ser positivos")
+	}
+	if *idnForm != "ascii" && *idnForm != "unicode" {
+		log.Fatal("A forma de -idn deve ser 'ascii' ou 'unicode'")
+	}
+	if *contextLines < 0 {
+		log.Fatal("O valor de -C deve ser positivo")
+	}
+	if *entropyThreshold <= 0 || *entropyThreshold > 6 {
+		log.Fatal("O valor de -entropy-threshold deve estar entre 0 e 6")
+	}
+	if *entropyMinLen < 1 {
+		log.Fatal("O valor de -entropy-min-len deve ser positivo")
+	}
+	if *maxPerFile < 0 || *maxPerRepo < 0 {
+		log.Fatal("Os limites -max-per-file e -max-per-repo devem ser positivos")
+	}
+	if *exportFormat != "" && exportFormats[*exportFormat] == nil {
+		log.Fatal("O formato de -export deve ser 'misp', 'stix', 'burp', 'zap', 'nuclei' ou 'remediation'")
+	}
+	if *outputProfile != "" && !outputProfiles[*outputProfile] {
+		log.Fatal("O perfil de -of deve ser 'subfinder', 'subfinder-json' ou 'amass'")
+	}
+	if *outputProfile != "" && (*exportFormat != "" || *top > 0) {
+		log.Fatal("-of não pode ser usado com -export ou -top")
+	}
+	if *profileSources && *outputProfile == "" {
+		log.Fatal("-sources exige -of")
+	}
+	if *nucleiTemplates != "" && *exportFormat != "nuclei" {
+		log.Fatal("-nuclei-templates exige -export nuclei")
+	}
+	if *exportFormat != "" && *top > 0 {
+		log.Fatal("-export não pode ser usado com -top")
+	}
+	if outputFormat != "" && (*exportFormat != "" || *outputProfile != "" || *top > 0) {
+		log.Fatal("O formato de -o não pode ser usado com -export, -of ou -top")
+	}
+	if *formatText != "" && (outputFormat != "" || *exportFormat != "" || *outputProfile != "" || *top > 0 || isJSONLPath(*outputPath)) {
+		log.Fatal("-format não pode ser usado com um formato de -o, -export, -of ou -top")
+	}
+	if *print0 && (outputFormat != "" || *exportFormat != "" || *outputProfile != "" || *top > 0 || isJSONLPath(*outputPath)) {
+		log.Fatal("-print0 não pode ser usado com um formato de -o, -export, -of ou -top")
+	}
+	if *print0 && *contextLines > 0 {
+		log.Fatal("-print0 não pode ser usado com -C")
+	}
+	// Sem formato explícito, o arquivo de -o define o formato pela extensão.
+	if outputFormat == "" && *formatText == "" && !*print0 && *exportFormat == "" && *outputProfile == "" && *top == 0 {
+		outputFormat = formatForPath(*outputPath)
+	}
+	if *sortOutput != "" && !sortOrders[*sortOutput] {
+		log.Fatal("A ordenação (-sort-output) deve ser 'alpha', 'count' ou 'repo'")
+	}
+	// Sem modo, a regex filtra os trechos; com modo, ela é aplicada sobre cada
+	// valor extraído (URL, domínio, e-mail...).
+	var patterns []string
+	if *regexStr != "" {
+		patterns = append(patterns, *regexStr)
+	}
+	if *patternsFile != "" {
+		filePatterns, err := loadPatterns(*patternsFile)
+		if err != nil {
+			log.Fatalf("Erro ao ler arquivo de padrões: %v", err)
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	re, err := compileMatcher(patterns, matchOptions{
+		engine:     *engine,
+		ignoreCase: *ignoreCase,
+		fixed:      *fixedStrings,
+		multiline:  *multiline,
+		dotall:     *dotall,
+	})
+	if err != nil {
+		log.Fatalf("Erro ao compilar a regex: %v", err)
+	}
+	// -m aceita vários modos separados por vírgula, todos aplicados aos mesmos
+	// trechos: uma única busca (que consome o limite da API) alimenta todos.
+	var modes []string
+	if *mode != "" {
+		for _, m := range strings.Split(*mode, ",") {
+			m = strings.TrimSpace(m)
+			if !contains(extractionModes, m) {
+				log.Fatalf("O modo (-m) deve ser um de: %s", strings.Join(extractionModes, ", "))
+			}
+			if !contains(modes, m) {
+				modes = append(modes, m)
+			}
+		}
+	}
+	// -patterns acrescenta o modo patterns aos de -m (ou o usa sozinho).
+	var packs *patternPacks
+	if *packPaths != "" {
+		if !contains(modes, "patterns") {
+			modes = append(modes, "patterns")
+		}
+		if packs, err = loadPatternPacks(strings.Split(*packPaths, ","), *ignoreCase); err != nil {
+			log.Fatalf("Erro ao ler -patterns: %v", err)
+		}
+	} else if contains(modes, "patterns") {
+		log.Fatal("O modo patterns exige os pacotes de extratores em -patterns")
+	}
 
-	// Loop de paginação.
-	for {
-		baseURL := "https://api.github.com/search/code"
-		// A query deve ser simples para a API.
-		q := url.QueryEscape(*apiQuery)
-		apiURL := fmt.Sprintf("%s?q=%s&page=%d&per_page=%d", baseURL, q, page, perPage)
+	// Além dos grupos da regex, alguns modos expõem campos próprios (claims
+	// do JWT, usuário e host das credenciais).
+	groupNames := append([]string{}, re.SubexpNames()...)
+	for _, m := range modes {
+		groupNames = append(groupNames, modeFields[m]...)
+	}
+	fields, err := parseFields(*fieldList, groupNames)
+	if err != nil {
+		log.Fatalf("Erro em -fields: %v", err)
+	}
+	var format *template.Template
+	if *formatText != "" {
+		if len(fields) > 0 {
+			log.Fatal("-format não pode ser usado com -fields")
+		}
+		if format, err = parseTemplate(*formatText); err != nil {
+			log.Fatalf("Erro em -format: %v", err)
+		}
+	}
+	if printGroup != "" && *invertMatch {
+		log.Fatal("-og não pode ser usado com -v-match, que não gera capturas")
+	}
+	if printGroup != "" && !hasGroup(re, printGroup) {
+		log.Fatalf("A regex de filtro não possui o grupo %q (-og)", printGroup)
+	}
 
-		req, err := http.NewRequest("GET", apiURL, nil)
-		if err != nil {
-			log.Fatalf("Erro ao criar requisição: %v", err)
+	ex := &extractor{
+		filter:     re,
+		stripQuery: *stripQuery,
+		ignoreCase: *ignoreCase,
+		printGroup: printGroup,
+		joinLines:  *joinLines,
+		invert:     *invertMatch,
+		idnForm:    *idnForm,
+		unescape:   !*noUnescape,
+		withPorts:  *withPorts,
+		publicIPs:  *publicIPs,
+		// Limites do modo entropy.
+		entropyThreshold: *entropyThreshold,
+		entropyMinLen:    *entropyMinLen,
+		// As senhas só aparecem na saída silenciosa, feita para outras ferramentas, ou com -unmask.
+		maskCreds:     !*silent && !*unmask,
+		strictDomains: *strictDomains,
+		refang:        *refang,
+		packs:         packs,
+	}
+	// O escopo do cliente só vale para os modos de extração.
+	var scopeEntries []string
+	if tenant != nil && len(modes) > 0 {
+		if *scopeFile == "" {
+			*scopeFile = tenant.ScopeFile
 		}
-		req.Header.Set("Accept", "application/vnd.github.v3.text-match+json")
-		if githubKey != "" {
-			req.Header.Set("Authorization", "token "+githubKey)
+		scopeEntries = tenant.Scope
+	}
+	if *scopeFile != "" || *targets != "" || len(scopeEntries) > 0 {
+		sc := newScope(*scopeApex)
+		if *scopeFile != "" {
+			if err := sc.load(*scopeFile); err != nil {
+				log.Fatalf("Erro ao ler arquivo de escopo: %v", err)
+			}
+		}
+		for _, e := range scopeEntries {
+			if err := sc.add(e); err != nil {
+				log.Fatalf("Erro no escopo do cliente: %v", err)
+			}
+		}
+		for _, t := range strings.Split(*targets, ",") {
+			if err := sc.addTarget(t); err != nil {
+				log.Fatalf("Erro em -t: %v", err)
+			}
 		}
+		if sc.empty() {
+			log.Fatal("O escopo (-scope/-t) não possui nenhuma entrada")
+		}
+		if len(modes) == 0 {
+			log.Fatal("O escopo (-scope/-t) só se aplica aos modos de extração (-m)")
+		}
+		ex.scope = sc
+	}
+	if *checkRegistry && !contains(modes, "packages") {
+		log.Fatal("-check-registry só se aplica ao modo packages")
+	}
+	if contains(modes, "subdomains") && ex.scope == nil {
+		log.Fatal("O modo subdomains exige o domínio alvo em -t (ou -scope)")
+	}
+	// Um extrator por modo, com as mesmas opções; sem modo, só o filtro.
+	extractors := []*extractor{ex}
+	if len(modes) > 0 {
+		extractors = nil
+		for _, m := range modes {
+			x := *ex
+			x.mode = m
+			extractors = append(extractors, &x)
+		}
+	}
 
-		resp, err := http.DefaultClient.Do(req)
+	client := &githubClient{apiURL: apiBase, retries: *retries, retryWait: *retryWait, quiet: *silent}
+	tokens, err := resolveTokens(*tokenList, tenant, *useGH, client)
+	if err != nil {
+		log.Fatalf("Erro ao ler -tokens: %v", err)
+	}
+	client.tokens = newTokenPool(tokens)
+	var registry *registryChecker
+	if *checkRegistry {
+		registry = newRegistryChecker(client)
+	}
+	// Sem nenhum token, a API de busca do GitHub só responde 401. O grep.app
+	// busca sem autenticação, mas não entende os qualificadores do GitHub
+	// (org:, filename:, extension:...), então só é usado quando pedido.
+	if *provider == "github" && len(tokens) == 0 && *appID == "" && *webQuery == "" && apiBase == "" && *replayDir == "" && !offline {
+		log.Fatal("Nenhum token do GitHub configurado: a busca de código da API exige autenticação (GITHUB_KEY, -tokens, -client, gfinder login ou -use-gh); para buscar sem token, use -provider grepapp")
+	}
+	if *provider == "grepapp" {
+		if terms := queryQualifiers(*apiQuery); len(terms) > 0 {
+			log.Printf("Aviso: o grep.app não entende qualificadores do GitHub e busca %s como texto; use -org para restringir a organização", strings.Join(terms, " "))
+		}
+	}
+	if *appID != "" {
+		app, err := newAppAuth(*appID, *appKey, *appInstallation, client)
+		if err != nil {
+			log.Fatalf("Erro ao ler a chave da GitHub App: %v", err)
+		}
+		client.tokens = newRefreshingPool(app.token)
+	}
+	var searcher codeSearcher = client
+	if *webQuery != "" {
+		searcher = &webSearcher{session: os.Getenv("GITHUB_SESSION"), client: client}
+	}
+	if *provider == "gitlab" {
+		// No GitLab, -org é o grupo (ou subgrupo, como acme/infra) da busca.
+		searcher = newGitLabSearcher(*gitlabBase, os.Getenv("GITLAB_TOKEN"), *org, client)
+	}
+	if *provider == "gitea" && *baseURL != "" {
+		if searcher, err = newGiteaSearcher(*baseURL, *org, os.Getenv("GITEA_COOKIE"), client); err != nil {
+			log.Fatalf("Erro em -base-url: %v", err)
+		}
+	}
+	if *provider == "sourcegraph" {
+		base := *baseURL
+		if base == "" {
+			base = sourcegraphURL
+		}
+		searcher = newSourcegraphSearcher(base, os.Getenv("SRC_ACCESS_TOKEN"), *org, *patternType, client)
+	}
+	if *provider == "grepapp" {
+		searcher = &grepAppSearcher{regexp: *patternType == "regexp", org: *org, client: client}
+	}
+	if *provider == "searchcode" {
+		searcher = &searchcodeSearcher{org: *org, client: client}
+	}
+	if *provider == "bitbucket" {
+		searcher = &bitbucketSearcher{workspace: *org, username: os.Getenv("BITBUCKET_USERNAME"), token: os.Getenv("BITBUCKET_TOKEN"), client: client}
+	}
+	if *recordDir != "" {
+		if client.recorder, err = newRecorder(*recordDir); err != nil {
+			log.Fatalf("Erro ao criar diretório de captura: %v", err)
+		}
+	}
+	if *auditPath != "" {
+		if client.audit, err = openAuditLog(*auditPath); err != nil {
+			log.Fatalf("Erro ao abrir log de auditoria: %v", err)
+		}
+		defer client.audit.Close()
+	}
+	var owners *ownerResolver
+	if *resolveOwners {
+		owners = newOwnerResolver(client)
+	}
+	if *apiMode == "graphql" {
+		searcher = newGraphQLSearcher(client, owners)
+	}
+	if withSearchcode {
+		searcher = newChainedSearcher(searcher, &searchcodeSearcher{org: *org, client: client})
+	}
+	if *replayDir != "" {
+		replay, query, err := loadCapture(*replayDir)
 		if err != nil {
-			log.Fatalf("Erro na requisição: %v", err)
+			log.Fatalf("Erro ao abrir captura de -replay: %v", err)
+		}
+		if *apiQuery == "" {
+			*apiQuery = query
 		}
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			log.Fatalf("Erro da API (status %d): %s", resp.StatusCode, string(body))
+		searcher = replay
+	}
+	if *org != "" && *provider == "github" {
+		*apiQuery += " org:" + *org
+	}
+
+	out := newPrinter(*silent, *unique, *dedupeBy, *sortOutput, *top)
+	out.maxPerFile = *maxPerFile
+	out.maxPerRepo = *maxPerRepo
+	out.fields = fields
+	out.format = format
+	out.print0 = *print0
+	out.context = *contextLines
+	out.labelModes = len(modes) > 1
+	if *exportFormat != "" {
+		out.export = exportFormats[*exportFormat](*apiQuery)
+		if n, ok := out.export.(*nucleiExporter); ok {
+			n.templateDir = *nucleiTemplates
 		}
+	}
+	if *outputProfile != "" {
+		out.export = newProfileExporter(*outputProfile, *profileSources)
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+	var maxSize int64
+	if *rotateSize != "" {
+		if maxSize, err = parseSize(*rotateSize); err != nil {
+			log.Fatalf("Erro em -rotate-size: %v", err)
+		}
+	}
+	if (*rotateDaily || maxSize > 0) && *outputPath == "" {
+		log.Fatal("A rotação (-rotate-daily/-rotate-size) exige um arquivo de saída com -o")
+	}
+	if (*rotateDaily || maxSize > 0) && atomicOutput {
+		log.Fatal("A rotação (-rotate-daily/-rotate-size) não pode ser usada com -output; use -o")
+	}
+	if *appendOutput && (*exportFormat != "" || (outputFormat != "" && outputFormat != "tsv")) {
+		log.Fatal("-append só se aplica a saídas em linhas (texto, JSONL, tsv ou -of), não a documentos completos")
+	}
+	if (*rotateDaily || maxSize > 0) && outputFormat != "" {
+		log.Fatalf("A rotação (-rotate-daily/-rotate-size) não se aplica ao formato %s, que é um documento único", outputFormat)
+	}
+	if *outputPath != "" {
+		var file io.WriteCloser
+		if *rotateDaily || maxSize > 0 {
+			file, err = newRotatingOutput(*outputPath, *rotateDaily, maxSize, *keep)
+		} else if atomicOutput {
+			file, err = openAtomicOutput(*outputPath, *appendOutput)
+		} else {
+			file, err = openOutput(*outputPath)
+		}
 		if err != nil {
-			log.Fatalf("Erro ao ler resposta: %v", err)
+			log.Fatalf("Erro ao criar arquivo de saída: %v", err)
+		}
+		out.w = file
+		out.color = false
+		if isJSONLPath(*outputPath) {
+			out.jsonl = newJSONLWriter(file)
 		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				fatalf("Erro ao gravar arquivo de saída: %v", err)
+			}
+		}()
+	}
+	if outputFormat != "" {
+		out.export = outputFormats[outputFormat](out.w, fields)
+	}
+	if *reportPath != "" {
+		out.report = newHTMLReport(*apiQuery)
+		// Gravado ao sair, depois do flush, tanto na busca quanto no modo offline.
+		defer func() {
+			if err := writeReport(*reportPath, out.report); err != nil {
+				fatalf("Erro ao gravar relatório de -report: %v", err)
+			}
+		}()
+	}
+	if *teePath != "" {
+		file, err := openOutput(*teePath)
+		if err != nil {
+			fatalf("Erro ao criar arquivo de -tee: %v", err)
+		}
+		out.tee = newJSONLWriter(file)
+		defer func() {
+			if err := file.Close(); err != nil {
+				fatalf("Erro ao gravar arquivo de -tee: %v", err)
+			}
+		}()
+	}
 
-		var result CodeSearchResult
-		if err := json.Unmarshal(body, &result); err != nil {
-			log.Fatalf("Erro ao decodificar JSON: %v", err)
+	// Com as senhas mascaradas no modo creds, o trecho na saída também as esconde.
+	maskFragments := contains(modes, "creds") && ex.maskCreds
+
+	// process extrai os valores de um trecho e os envia para a saída.
+	process := func(item CodeSearchItem, fragment string, page int) {
+		repo, path := item.Repository.FullName, item.Path
+		var owner string
+		resolved := false
+		shown, position := fragment, func(p int) int { return p }
+		if maskFragments {
+			shown, position = maskCredentials(fragment)
+		}
+		for _, ex := range extractors {
+			extractions := ex.extract(fragment)
+			if registry != nil && ex.mode == "packages" {
+				if err := registry.mark(extractions); err != nil {
+					log.Printf("Aviso: não foi possível consultar o registro de pacotes: %v", err)
+				}
+			}
+			if owners != nil && len(extractions) > 0 && !resolved {
+				if owner, err = owners.resolve(repo, path); err != nil {
+					fatalf("Erro ao identificar o responsável por %s/%s: %v", repo, path, err)
+				}
+				resolved = true
+			}
+			for _, x := range extractions {
+				rule := ex.rule()
+				if x.Rule != "" {
+					rule = x.Rule
+				}
+				out.emit(Finding{
+					Query:    *apiQuery,
+					Repo:     repo,
+					Path:     path,
+					FileURL:  item.HTMLURL,
+					Fragment: shown,
+					Match:    x.Value,
+					Mode:     ex.mode,
+					Rule:     rule,
+					Page:     page,
+					Groups:   x.Groups,
+					Severity: x.Severity,
+					Tags:     x.Tags,
+					Owner:    owner,
+					Language: item.Repository.Language,
+					Stars:    item.Repository.Stars,
+					Start:    position(x.Start),
+					End:      position(x.End),
+				})
+			}
+		}
+	}
+
+	if offline {
+		paths := flag.Args()
+		if *inputPaths != "" {
+			paths = append(strings.Split(*inputPaths, ","), paths...)
+		}
+		if err := readInputs(paths, func(name, content string) {
+			process(CodeSearchItem{Path: name, HTMLURL: name}, content, 0)
+		}); err != nil {
+			fatalf("Erro ao ler a entrada: %v", err)
+		}
+		out.flush()
+		return
+	}
+
+	perPage := 100 // Máximo permitido pela API.
+	page := 1
+
+	// Mensagem exibida ao final, depois dos resultados.
+	var status string
+
+	// Loop de paginação.
+	for {
+		result, err := searcher.searchCode(*apiQuery, page, perPage)
+		if err != nil {
+			fatalf("Erro na busca: %v", err)
 		}
 
 		// Se não houver itens, encerra a busca.
 		if len(result.Items) == 0 {
-			if !*silent {
-				fmt.Println("Nenhum resultado encontrado ou fim dos resultados disponíveis.")
-			}
+			status = "Nenhum resultado encontrado ou fim dos resultados disponíveis."
 			break
 		}
 
 		// Processa cada item retornado e aplica o filtro.
 		for _, item := range result.Items {
 			for _, tm := range item.TextMatches {
-				if *mode == "" {
-					// Sem modo, usa a regex passada para filtrar os trechos.
-					matches := re.FindAllString(tm.Fragment, -1)
-					for _, m := range matches {
-						if *silent {
-							// Se silent, exibe somente o resultado, garantindo que seja único.
-							if !uniqueResults[m] {
-								fmt.Println(m)
-								uniqueResults[m] = true
-							}
-						} else {
-							fmt.Printf("\033[34m%s\033[0m - \033[32m%s\033[0m\n", item.HTMLURL, m)
-						}
-					}
-				} else {
-					// Com modo, extrai URLs usando a regex interna.
-					urls := urlRegex.FindAllString(tm.Fragment, -1)
-					for _, u := range urls {
-						if *mode == "domains" {
-							domain := extractDomain(u)
-							if domain != "" && re.MatchString(domain) {
-								if *silent {
-									if !uniqueResults[domain] {
-										fmt.Println(domain)
-										uniqueResults[domain] = true
-									}
-								} else {
-									fmt.Printf("\033[34m%s\033[0m - \033[32m%s\033[0m\n", item.HTMLURL, domain)
-								}
-							}
-						} else if *mode == "urls" {
-							if re.MatchString(u) {
-								if *silent {
-									if !uniqueResults[u] {
-										fmt.Println(u)
-										uniqueResults[u] = true
-									}
-								} else {
-									fmt.Printf("\033[34m%s\033[0m - \033[32m%s\033[0m\n", item.HTMLURL, u)
-								}
-							}
-						}
-					}
-				}
+				process(item, tm.Fragment, page)
 			}
 		}
 
-		// A API do GitHub retorna no máximo 1000 resultados (10 páginas com 100 itens cada).
-		if page*perPage >= result.TotalCount || page >= 10 {
-			if !*silent {
-				fmt.Println("Fim dos resultados disponíveis.")
-			}
+		if result.last {
+			status = "Fim dos resultados disponíveis."
 			break
 		}
 
 		page++
-		time.Sleep(time.Duration(*delay) * time.Second)
+		switch {
+		case *replayDir != "":
+		case fixedDelay:
+			time.Sleep(time.Duration(*delay) * time.Second)
+		case *webQuery != "" || *provider != "github":
+			// A busca pelo site e os outros provedores não informam o limite
+			// como a API do GitHub; mantém o intervalo antigo.
+			time.Sleep(2 * time.Second)
+		default:
+			client.pace()
+		}
+	}
+
+	out.flush()
+	if *poolStats {
+		client.tokens.writeStats(os.Stderr)
+	}
+	if !*silent {
+		// Documentos estruturados na saída padrão não podem receber a mensagem.
+		if out.structured() {
+			fmt.Fprintln(os.Stderr, status)
+		} else {
+			fmt.Println(status)
+		}
 	}
 }
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// valueModes são os modos de -m que procuram seus próprios valores no trecho,
+// em vez de partir das URLs encontradas. Cada um devolve os candidatos com a
+// posição no trecho; o escopo e a regex de filtro são aplicados depois.
+var valueModes = map[string]func(e *extractor, fragment string) []extraction{
+	"emails":      findEmails,
+	"ips":         findIPs,
+	"cidrs":       findCIDRs,
+	"subdomains":  findSubdomains,
+	"s3":          findS3Buckets,
+	"buckets":     findStorageBuckets,
+	"secrets":     findSecrets,
+	"jwt":         findJWTs,
+	"privatekeys": findPrivateKeys,
+	"creds":       findCreds,
+	"endpoints":   findEndpoints,
+	"params":      findParams,
+	"env":         findEnv,
+	"webhooks":    findWebhooks,
+	"hostports":   findHostPorts,
+	"wallets":     findWallets,
+	"internal":    findInternalHosts,
+	"docker":      findDockerImages,
+	"packages":    findPackages,
+	"graphql":     findGraphQL,
+	"entropy":     findHighEntropy,
+	"firebase":    findFirebase,
+	"patterns":    findCustomPatterns,
+}
+
+// modeFields são os campos extras (em Groups) que alguns modos acrescentam a
+// cada resultado, aceitos em -fields como os grupos nomeados da regex.
+var modeFields = map[string][]string{
+	"jwt":      jwtFields,
+	"creds":    credFields,
+	"env":      envFields,
+	"docker":   dockerFields,
+	"entropy":  entropyFields,
+	"firebase": firebaseFields,
+}
+
+// emailRegex encontra candidatos a e-mail; as pontas e o domínio são
+// validados em findEmails, já que o RE2 não tem lookarounds.
+var emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// findEmails extrai endereços de e-mail com domínio válido (TLD existente na
+// Public Suffix List), o que descarta falsos positivos como logo@2x.png. O
+// domínio sai em minúsculas; a parte local é mantida como escrita.
+func findEmails(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, loc := range emailRegex.FindAllStringIndex(fragment, -1) {
+		start, end := loc[0], loc[1]
+		// Pontos e hífens nas pontas são pontuação do texto, não do endereço.
+		for start < end && strings.ContainsRune(".-", rune(fragment[start])) {
+			start++
+		}
+		for end > start && strings.ContainsRune(".-", rune(fragment[end-1])) {
+			end--
+		}
+		local, domain, ok := strings.Cut(fragment[start:end], "@")
+		if !ok || local == "" || strings.Contains(local, "..") {
+			continue
+		}
+		domain = normalizeIDN(strings.ToLower(domain), e.idnForm)
+		if !validHostname(domain) {
+			continue
+		}
+		found = append(found, extraction{Value: local + "@" + domain, Start: start, End: end, host: domain})
+	}
+	return found
+}
+
+// Candidatos a IPv4 e IPv6; a validação fica com o netip.
+var (
+	ipv4Regex = regexp.MustCompile(`[0-9]{1,3}(?:\.[0-9]{1,3}){3}`)
+	ipv6Regex = regexp.MustCompile(`[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}`)
+)
+
+// reservedPrefixes são faixas que não identificam hosts na internet pública,
+// além das que o netip já reconhece (privadas, loopback, link-local, multicast).
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("100::/64"),
+}
+
+// isPublicIP indica se o endereço é roteável na internet pública.
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsMulticast() ||
+		ip.IsUnspecified() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordByte indica se o byte continua um identificador ou número, caso em
+// que um candidato colado a ele é só parte de um valor maior.
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
+
+// findIPs extrai endereços IPv4 e IPv6. Candidatos colados a letras, números
+// ou pontos (versões como 1.2.3.4.5, Foo::Bar) são descartados; IPv6 sem
+// nenhum dígito decimal também, por serem quase sempre código (A::B).
+func findIPs(e *extractor, fragment string) []extraction {
+	var found []extraction
+	add := func(start, end int) {
+		ip, err := netip.ParseAddr(fragment[start:end])
+		if err != nil || (e.publicIPs && !isPublicIP(ip)) {
+			return
+		}
+		value := ip.String()
+		found = append(found, extraction{Value: value, Start: start, End: end, host: value})
+	}
+	for _, loc := range ipv4Regex.FindAllStringIndex(fragment, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && (isWordByte(fragment[start-1]) || fragment[start-1] == '.') {
+			continue
+		}
+		if end < len(fragment) && (isWordByte(fragment[end]) ||
+			fragment[end] == '.' && end+1 < len(fragment) && isWordByte(fragment[end+1])) {
+			continue
+		}
+		add(start, end)
+	}
+	for _, loc := range ipv6Regex.FindAllStringIndex(fragment, -1) {
+		start, end := loc[0], loc[1]
+		candidate := fragment[start:end]
+		if !strings.ContainsAny(candidate, "0123456789") {
+			continue
+		}
+		if start > 0 && (isWordByte(fragment[start-1]) || fragment[start-1] == ':' || fragment[start-1] == '.') {
+			continue
+		}
+		if end < len(fragment) && (isWordByte(fragment[end]) || fragment[end] == '.') {
+			continue
+		}
+		add(start, end)
+	}
+	// Os IPv6 vêm depois dos IPv4; a ordem do trecho é restaurada.
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// Candidatos a faixas em notação CIDR, IPv4 e IPv6; a validação fica com o netip.
+var (
+	cidr4Regex = regexp.MustCompile(`[0-9]{1,3}(?:\.[0-9]{1,3}){3}/[0-9]{1,2}`)
+	cidr6Regex = regexp.MustCompile(`[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}/[0-9]{1,3}`)
+)
+
+// findCIDRs extrai faixas de rede em notação CIDR (10.0.0.0/8, 2001:db8::/32),
+// comuns em infraestrutura como código, para montar o escopo. A faixa sai
+// normalizada, sem os bits de host (10.1.2.3/8 vira 10.0.0.0/8), com a tag
+// public ou private; /0 (qualquer endereço) é ignorado e, com -public-ips,
+// as faixas privadas e reservadas também.
+func findCIDRs(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, re := range []*regexp.Regexp{cidr4Regex, cidr6Regex} {
+		for _, loc := range re.FindAllStringIndex(fragment, -1) {
+			start, end := loc[0], loc[1]
+			if start > 0 && (isWordByte(fragment[start-1]) || strings.ContainsRune(".:", rune(fragment[start-1]))) {
+				continue
+			}
+			if end < len(fragment) && (isWordByte(fragment[end]) ||
+				fragment[end] == '.' && end+1 < len(fragment) && isWordByte(fragment[end+1])) {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(fragment[start:end])
+			if err != nil || prefix.Bits() == 0 {
+				continue
+			}
+			prefix = prefix.Masked()
+			tag := "public"
+			if !isPublicIP(prefix.Addr()) {
+				if e.publicIPs {
+					continue
+				}
+				tag = "private"
+			}
+			found = append(found, extraction{Value: prefix.String(), Start: start, End: end, Tags: []string{tag}})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// hostnameRegex encontra nomes de host soltos no texto (sem esquema).
+var hostnameRegex = regexp.MustCompile(`(?i)[a-z0-9](?:[a-z0-9\-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9\-]*[a-z0-9])?)+`)
+
+// findSubdomains extrai nomes de host em qualquer ponto do trecho (dentro de
+// URLs, strings, configurações, e-mails); o escopo de -t ou -scope decide
+// quais pertencem ao domínio alvo.
+func findSubdomains(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, loc := range hostnameRegex.FindAllStringIndex(fragment, -1) {
+		start, end := loc[0], loc[1]
+		// Escapes de string (\napi.example.com) grudam uma letra no nome.
+		if start > 0 && fragment[start-1] == '\\' && strings.ContainsRune("nrtNRT", rune(fragment[start])) {
+			start++
+		}
+		host := normalizeIDN(strings.ToLower(fragment[start:end]), e.idnForm)
+		found = append(found, extraction{Value: host, Start: start, End: end, host: host})
+	}
+	return found
+}
+
+// s3Regex reconhece as três formas de referência a um bucket S3: s3://bucket,
+// bucket.s3[.-região].amazonaws.com (virtual-hosted) e
+// s3[.-região].amazonaws.com/bucket (path-style). O nome fica no grupo 1, 2
+// ou 3, conforme a forma.
+var s3Regex = regexp.MustCompile(`(?i)s3://([a-z0-9][a-z0-9.\-]{1,61}[a-z0-9])` +
+	`|([a-z0-9][a-z0-9.\-]{1,61}[a-z0-9])\.s3(?:[.\-][a-z0-9\-]+)*\.amazonaws\.com` +
+	`|s3(?:[.\-][a-z0-9\-]+)*\.amazonaws\.com/([a-z0-9][a-z0-9.\-]{1,61}[a-z0-9])`)
+
+// findS3Buckets extrai os nomes dos buckets S3 referenciados no trecho, em
+// minúsculas, para testes de takeover e de permissões.
+func findS3Buckets(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, m := range s3Regex.FindAllStringSubmatchIndex(fragment, -1) {
+		for g := 1; g <= 3; g++ {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 {
+				continue
+			}
+			bucket := strings.ToLower(fragment[start:end])
+			// Nomes com pontos seguidos ou em forma de IP não são aceitos pela AWS.
+			if _, err := netip.ParseAddr(bucket); err == nil || strings.Contains(bucket, "..") {
+				break
+			}
+			found = append(found, extraction{Value: bucket, Start: start, End: end})
+			break
+		}
+	}
+	return found
+}
+
+// Referências a armazenamento no Google Cloud Storage (gs://bucket,
+// storage.googleapis.com/bucket, bucket.storage.googleapis.com) e no Azure
+// Blob Storage (conta.blob.core.windows.net/contêiner).
+var (
+	gcsRegex = regexp.MustCompile(`(?i)gs://([a-z0-9][a-z0-9._\-]{1,220}[a-z0-9])` +
+		`|storage\.(?:googleapis|cloud\.google)\.com/([a-z0-9][a-z0-9._\-]{1,220}[a-z0-9])` +
+		`|([a-z0-9][a-z0-9._\-]{1,220}[a-z0-9])\.storage\.googleapis\.com`)
+	azureBlobRegex = regexp.MustCompile(`(?i)([a-z0-9]{3,24})\.blob\.core\.windows\.net(?:/(\$root|\$web|[a-z0-9][a-z0-9\-]{1,61}[a-z0-9]))?`)
+)
+
+// findStorageBuckets extrai buckets do GCS, emitidos como gs://bucket, e
+// contêineres do Azure, emitidos como conta.blob.core.windows.net/contêiner
+// (ou só a conta, quando o contêiner não aparece). Cada valor leva a tag do
+// provedor.
+func findStorageBuckets(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, m := range gcsRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		for g := 1; g <= 3; g++ {
+			if m[2*g] >= 0 {
+				bucket := strings.ToLower(fragment[m[2*g]:m[2*g+1]])
+				found = append(found, extraction{Value: "gs://" + bucket, Start: m[0], End: m[1], Tags: []string{"gcs"}})
+				break
+			}
+		}
+	}
+	for _, m := range azureBlobRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		value := strings.ToLower(fragment[m[2]:m[3]]) + ".blob.core.windows.net"
+		if m[4] >= 0 {
+			value += "/" + strings.ToLower(fragment[m[4]:m[5]])
+		}
+		found = append(found, extraction{Value: value, Start: m[0], End: m[1], Tags: []string{"azure"}})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// endpointRegex encontra caminhos relativos entre aspas ("/api/v2/users") ou
+// depois de um método HTTP (GET /internal/admin). O caminho fica no grupo 1
+// ou no grupo 2.
+var endpointRegex = regexp.MustCompile("[\"'`](/[A-Za-z0-9_\\-.~%{}:$@]+(?:/[A-Za-z0-9_\\-.~%{}:$@]*)*(?:\\?[^\"'`\\s]*)?)[\"'`]" +
+	`|\b(?:GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)\s+(/[A-Za-z0-9_\-.~%{}:$@]+(?:/[A-Za-z0-9_\-.~%{}:$@]*)*(?:\?\S*)?)`)
+
+// Caminhos do sistema de arquivos e arquivos estáticos, que não são endpoints.
+var (
+	systemPathPrefixes = []string{"/usr/", "/etc/", "/bin/", "/sbin/", "/var/", "/tmp/", "/dev/", "/proc/", "/sys/", "/home/", "/opt/", "/lib/", "/root/", "/mnt/", "/Users/", "/Library/", "/System/"}
+	staticExtensions   = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".webp", ".css", ".woff", ".woff2", ".ttf", ".eot", ".map", ".mp4", ".mp3"}
+)
+
+// findEndpoints extrai caminhos de API e páginas relativos, para listas de
+// palavras e descoberta de conteúdo. Caminhos do sistema e arquivos
+// estáticos (imagens, fontes, CSS) são descartados; a query string é mantida,
+// salvo com -strip-query.
+func findEndpoints(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, m := range endpointRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		start, end := m[2], m[3]
+		if start < 0 {
+			start, end = m[4], m[5]
+		}
+		path := trimURLPunctuation(fragment[start:end])
+		end = start + len(path)
+		if e.stripQuery {
+			path, _, _ = strings.Cut(path, "?")
+		}
+		route, _, _ := strings.Cut(path, "?")
+		if route == "/" || strings.HasPrefix(route, "//") || hasAnyPrefix(route, systemPathPrefixes) || hasAnySuffix(strings.ToLower(route), staticExtensions) {
+			continue
+		}
+		found = append(found, extraction{Value: path, Start: start, End: end})
+	}
+	return found
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// paramRegex encontra nomes de parâmetros em query strings (?id=, &token=),
+// em campos de formulário (<input name="email">) e em leituras de parâmetros
+// no código (searchParams.get("redirect"), formData.append('file', ...)). O
+// nome fica no grupo 1, 2 ou 3.
+var paramRegex = regexp.MustCompile(`[?&]([A-Za-z_][A-Za-z0-9_\-.\[\]]*)=` +
+	`|(?i)<(?:input|select|textarea)\b[^>]*?\bname\s*=\s*["']([^"'\s>]+)["']` +
+	`|(?i)(?:searchParams|params|query|formData|request\.args|request\.form|\$_GET|\$_POST|\$_REQUEST)(?:\.(?:get|getAll|set|append|has)\(\s*|\[\s*)["']([A-Za-z_][A-Za-z0-9_\-.]*)["']`)
+
+// findParams extrai nomes de parâmetros para ferramentas de fuzzing como
+// arjun e x8. Cada nome aparece uma vez por trecho; use -s para deduplicar
+// entre trechos.
+func findParams(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	seen := make(map[string]bool)
+	for _, m := range paramRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		for g := 1; g <= 3; g++ {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 {
+				continue
+			}
+			name := fragment[start:end]
+			if !seen[name] {
+				seen[name] = true
+				found = append(found, extraction{Value: name, Start: start, End: end})
+			}
+			break
+		}
+	}
+	return found
+}
+
+// hostPortRegex encontra pares host:porta, com o host como nome, IPv4 ou
+// IPv6 entre colchetes.
+var hostPortRegex = regexp.MustCompile(`(?i)(\[[0-9a-f:.]+\]|[a-z0-9](?:[a-z0-9\-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9\-]*[a-z0-9])?)+):([0-9]{1,5})`)
+
+// findHostPorts extrai alvos host:porta, dentro de URLs ou soltos em
+// configurações (db.example.com:5432, 10.0.0.5:6379), prontos para naabu ou
+// nmap. O host precisa ser um IP, um nome com TLD existente ou um nome
+// interno conhecido (.local, .corp...); a porta, de 1 a 65535.
+func findHostPorts(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, m := range hostPortRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		if m[0] > 0 && (isWordByte(fragment[m[0]-1]) || fragment[m[0]-1] == '.') {
+			continue
+		}
+		// Um dígito ou ":" logo depois indica outro formato (horários, IPv6 sem colchetes).
+		if m[1] < len(fragment) && (isWordByte(fragment[m[1]]) || fragment[m[1]] == ':') {
+			continue
+		}
+		host := strings.ToLower(strings.Trim(fragment[m[2]:m[3]], "[]"))
+		port := fragment[m[4]:m[5]]
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			continue
+		}
+		if ip, err := netip.ParseAddr(host); err == nil {
+			host = ip.String()
+		} else if strings.HasPrefix(fragment[m[2]:m[3]], "[") || !validHostname(host) && !isInternalHost(host) {
+			continue
+		} else {
+			host = normalizeIDN(host, e.idnForm)
+		}
+		found = append(found, extraction{Value: net.JoinHostPort(host, port), Start: m[0], End: m[1], host: host})
+	}
+	return found
+}
+
+// reverseDNSRoots são os TLDs que iniciam nomes de pacote em notação reversa.
+// Outros TLDs (dev, app, ci, me) são nomes de host comuns em redes internas.
+var reverseDNSRoots = []string{"com", "org", "net", "edu", "gov", "io"}
+
+// findInternalHosts extrai nomes de hosts internos: os de sufixo interno
+// (.local, .corp, .internal, .lan...) em qualquer ponto do trecho e os de um
+// rótulo só (http://jenkins/) dentro de URLs, onde não há ambiguidade.
+// Nomes de pacote em notação reversa (com.acme.internal) e arquivos como
+// .env.local são ignorados.
+func findInternalHosts(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, loc := range hostnameRegex.FindAllStringIndex(fragment, -1) {
+		if loc[0] > 0 && fragment[loc[0]-1] == '.' {
+			continue
+		}
+		host := strings.ToLower(fragment[loc[0]:loc[1]])
+		if !isInternalHost(host) {
+			continue
+		}
+		first, _, _ := strings.Cut(host, ".")
+		if contains(reverseDNSRoots, first) {
+			continue
+		}
+		found = append(found, extraction{Value: host, Start: loc[0], End: loc[1], host: host})
+	}
+	urlRe := urlRegex
+	if e.ignoreCase {
+		urlRe = urlRegexFold
+	}
+	for _, loc := range urlRe.FindAllStringIndex(fragment, -1) {
+		raw := trimURLPunctuation(fragment[loc[0]:loc[1]])
+		host := extractDomain(raw)
+		if host == "" || strings.Contains(host, ".") || !isInternalHost(host) {
+			continue
+		}
+		start, end := loc[0], loc[0]+len(raw)
+		// As posições vêm de raw, não de uma cópia em minúsculas, que pode
+		// ter outro tamanho em bytes.
+		if i, j := indexFold(raw, host); i >= 0 {
+			start, end = loc[0]+i, loc[0]+j
+		}
+		found = append(found, extraction{Value: host, Start: start, End: end, host: host})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// dockerRefRegex encontra referências a imagens em Dockerfiles (FROM), em
+// manifestos do Compose e do Kubernetes (image:) e em comandos docker
+// pull/push. A referência fica no grupo 1, 2 ou 3.
+var dockerRefRegex = regexp.MustCompile(`(?im)^[ \t]*FROM[ \t]+(?:--platform=\S+[ \t]+)?([^\s"']+)` +
+	`|(?im)^[ \t]*-?[ \t]*image:[ \t]*["']?([^\s"'#]+)` +
+	`|(?i)\bdocker[ \t]+(?:pull|push)[ \t]+(?:-\S+[ \t]+)*([^\s"'-][^\s"']*)`)
+
+// imageRefRegex valida uma referência: [registro[:porta]/]caminho[:tag][@digest].
+var imageRefRegex = regexp.MustCompile(`^(?:([a-zA-Z0-9.\-]+(?::[0-9]+)?)/)?([a-z0-9]+(?:[._\-]+[a-z0-9]+)*(?:/[a-z0-9]+(?:[._\-]+[a-z0-9]+)*)*)(?::([A-Za-z0-9_][A-Za-z0-9_.\-]{0,127}))?(?:@sha256:[a-f0-9]{64})?$`)
+
+// publicRegistries são os registros públicos conhecidos; os demais são
+// marcados como private-registry.
+var publicRegistries = []string{"docker.io", "index.docker.io", "registry-1.docker.io", "ghcr.io", "quay.io", "gcr.io", "mcr.microsoft.com", "public.ecr.aws", "registry.k8s.io", "k8s.gcr.io", "registry.gitlab.com"}
+
+// dockerFields são os campos que o modo docker acrescenta a cada resultado.
+var dockerFields = []string{"registry", "image", "tag"}
+
+// findDockerImages extrai referências a imagens de contêiner, com o registro
+// (vazio para o Docker Hub), o caminho e a tag como campos. Imagens de
+// registros fora da lista de públicos recebem a tag private-registry, para
+// descobrir registros internos e as convenções de nome das imagens.
+func findDockerImages(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, m := range dockerRefRegex.FindAllStringSubmatchIndex(fragment, -1) {
+		for g := 1; g <= 3; g++ {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 {
+				continue
+			}
+			ref := fragment[start:end]
+			parts := imageRefRegex.FindStringSubmatch(ref)
+			if parts == nil || ref == "scratch" {
+				break
+			}
+			registry, image, tag := strings.ToLower(parts[1]), parts[2], parts[3]
+			// Sem ponto, dois-pontos ou localhost, o primeiro componente é parte
+			// do caminho no Docker Hub (library/nginx), não um registro.
+			if registry != "" && !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+				image, registry = parts[1]+"/"+image, ""
+			}
+			x := extraction{
+				Value:  ref,
+				Start:  start,
+				End:    end,
+				Groups: map[string]string{"image": image},
+			}
+			if tag != "" {
+				x.Groups["tag"] = tag
+			}
+			if registry != "" {
+				x.Groups["registry"] = registry
+				hostname := registry
+				if h, _, err := net.SplitHostPort(registry); err == nil {
+					hostname = h
+				}
+				x.host = hostname
+				if !contains(publicRegistries, registry) {
+					x.Tags = []string{"private-registry"}
+				}
+			}
+			found = append(found, x)
+			break
+		}
+	}
+	return found
+}
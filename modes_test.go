@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestFindInternalHostsPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     []string
+	}{
+		{"sufixo interno", "db = Kube.corp:5432", []string{"Kube.corp"}},
+		{"rótulo único em URL", "curl http://JENKINS:8080/job", []string{"JENKINS"}},
+		// Ⱥ tem 2 bytes e ⱥ, 3: a busca numa cópia em minúsculas saía do trecho.
+		{"minúscula mais longa", "see http://ȺȺȺȺ/x", []string{"ȺȺȺȺ"}},
+		{"sinal de Kelvin", "http://\u212aube/ e ci.acme.local", []string{"\u212aube", "ci.acme.local"}},
+		{"notação reversa", "import com.acme.internal.Foo", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := newTestExtractor("internal").extract(tt.fragment)
+			if len(values) != len(tt.want) {
+				t.Fatalf("%d resultados; quer %d", len(values), len(tt.want))
+			}
+			for i, x := range values {
+				if x.Start < 0 || x.End > len(tt.fragment) || x.Start > x.End {
+					t.Fatalf("posição [%d:%d] fora do trecho de %d bytes", x.Start, x.End, len(tt.fragment))
+				}
+				if got := tt.fragment[x.Start:x.End]; got != tt.want[i] {
+					t.Errorf("trecho[%d:%d] = %q; quer %q", x.Start, x.End, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
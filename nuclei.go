@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// nucleiSeverities são as severidades aceitas pelos templates do nuclei.
+var nucleiSeverities = []string{"info", "low", "medium", "high", "critical"}
+
+// nucleiExporter grava uma lista de alvos para o nuclei (-l) e, opcionalmente,
+// um template por endpoint encontrado para reconferir se ele segue exposto.
+type nucleiExporter struct {
+	set targetSet
+	// templateDir recebe os templates gerados (-nuclei-templates).
+	templateDir string
+	endpoints   []Finding
+	seen        map[string]bool
+}
+
+func newNucleiExporter(string) exporter {
+	return &nucleiExporter{seen: make(map[string]bool)}
+}
+
+func (e *nucleiExporter) add(f Finding) {
+	e.set.add(f)
+	if kind, value := classify(f); kind == "url" && !e.seen[value] {
+		e.seen[value] = true
+		e.endpoints = append(e.endpoints, f)
+	}
+}
+
+func (e *nucleiExporter) write(w io.Writer) error {
+	for _, u := range e.set.targets() {
+		if _, err := fmt.Fprintln(w, u.String()); err != nil {
+			return err
+		}
+	}
+	if e.templateDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(e.templateDir, 0o755); err != nil {
+		return err
+	}
+	for _, f := range e.endpoints {
+		id := "gfinder-" + fingerprint(f)
+		path := filepath.Join(e.templateDir, id+".yaml")
+		if err := os.WriteFile(path, []byte(nucleiTemplate(id, f)), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nucleiTemplate monta um template HTTP que repete o GET no endpoint do
+// resultado e casa quando ele ainda responde 200. O caminho é relativo ao
+// {{RootURL}}, então o template roda contra a origem de cada alvo da lista.
+func nucleiTemplate(id string, f Finding) string {
+	raw := f.Match
+	if strings.HasPrefix(raw, "//") {
+		raw = "https:" + raw
+	}
+	path := "/"
+	host := raw
+	if u, err := url.Parse(raw); err == nil {
+		host = u.Host
+		if u.EscapedPath() != "" {
+			path = u.EscapedPath()
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+	}
+	severity := f.Severity
+	if !contains(nucleiSeverities, severity) {
+		severity = "info"
+	}
+	tags := append([]string{"gfinder", "exposure"}, f.Tags...)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %s\n\n", id)
+	b.WriteString("info:\n")
+	fmt.Fprintf(&b, "  name: %s\n", strconv.Quote("Endpoint exposto em "+host))
+	b.WriteString("  author: gfinder\n")
+	fmt.Fprintf(&b, "  severity: %s\n", severity)
+	fmt.Fprintf(&b, "  description: %s\n", strconv.Quote("Encontrado em "+f.FileURL))
+	fmt.Fprintf(&b, "  reference:\n    - %s\n", strconv.Quote(f.FileURL))
+	fmt.Fprintf(&b, "  tags: %s\n\n", strings.Join(tags, ","))
+	b.WriteString("http:\n")
+	b.WriteString("  - method: GET\n")
+	fmt.Fprintf(&b, "    path:\n      - %s\n", strconv.Quote("{{RootURL}}"+path))
+	b.WriteString("    matchers:\n")
+	b.WriteString("      - type: status\n")
+	b.WriteString("        status:\n          - 200\n")
+	return b.String()
+}
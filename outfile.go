@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// pendingTemps são os arquivos temporários das escritas atômicas ainda não
+// concluídas. Uma execução encerrada antes do Close (log.Fatal ou Ctrl+C)
+// os apaga com removeTemps, em vez de deixá-los no diretório.
+var pendingTemps = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: make(map[string]bool)}
+
+// removeTemps apaga os temporários das escritas atômicas pendentes.
+func removeTemps() {
+	pendingTemps.Lock()
+	defer pendingTemps.Unlock()
+	for name := range pendingTemps.names {
+		os.Remove(name)
+		delete(pendingTemps.names, name)
+	}
+}
+
+// interrupted instala, uma única vez, o tratamento de Ctrl+C e SIGTERM que
+// apaga os temporários antes de sair, com o código de saída usual do shell
+// (128 + o sinal).
+var interrupted sync.Once
+
+func removeTempsOnInterrupt() {
+	interrupted.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			sig := <-signals
+			removeTemps()
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+			os.Exit(code)
+		}()
+	})
+}
+
+// fatalf encerra a execução como log.Fatalf, apagando antes os temporários
+// das escritas atômicas.
+func fatalf(format string, v ...any) {
+	removeTemps()
+	log.Fatalf(format, v...)
+}
+
+// outputFile é o destino de -o. Escritas passam por um buffer e, quando o nome
+// termina em .gz, são compactadas com gzip de forma transparente.
+type outputFile struct {
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	// final é o nome definitivo de uma escrita atômica (-output): o arquivo
+	// temporário só é renomeado para ele no Close.
+	final string
+}
+
+func openOutput(path string) (*outputFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return wrapOutput(file, path), nil
+}
+
+// openAtomicOutput grava num arquivo temporário no mesmo diretório, que só
+// substitui path no Close; uma execução interrompida não deixa o arquivo pela
+// metade. Com appendTo, o conteúdo atual de path é copiado antes dos novos
+// resultados (em .gz, o novo trecho vira outro membro gzip, que gunzip lê em
+// sequência).
+func openAtomicOutput(path string, appendTo bool) (*outputFile, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if appendTo {
+		if err := copyExisting(file, path); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return nil, err
+		}
+	}
+	pendingTemps.Lock()
+	pendingTemps.names[file.Name()] = true
+	pendingTemps.Unlock()
+	removeTempsOnInterrupt()
+	o := wrapOutput(file, path)
+	o.final = path
+	return o, nil
+}
+
+// copyExisting copia o conteúdo atual de path, se o arquivo existir.
+func copyExisting(w io.Writer, path string) error {
+	src, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(w, src)
+	return err
+}
+
+func wrapOutput(file *os.File, path string) *outputFile {
+	o := &outputFile{file: file}
+	var w io.Writer = file
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		o.gz = gzip.NewWriter(file)
+		w = o.gz
+	}
+	o.buf = bufio.NewWriter(w)
+	return o
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	return o.buf.Write(p)
+}
+
+// Close descarrega o buffer, finaliza o stream gzip e fecha o arquivo; numa
+// escrita atômica, também o move para o nome definitivo.
+func (o *outputFile) Close() error {
+	err := o.buf.Flush()
+	if o.gz != nil {
+		if gzErr := o.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	if closeErr := o.file.Close(); err == nil {
+		err = closeErr
+	}
+	if o.final != "" {
+		pendingTemps.Lock()
+		delete(pendingTemps.names, o.file.Name())
+		pendingTemps.Unlock()
+		if err != nil {
+			os.Remove(o.file.Name())
+			return err
+		}
+		return os.Rename(o.file.Name(), o.final)
+	}
+	return err
+}
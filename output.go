@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Finding representa um valor extraído de um trecho retornado pela API.
+type Finding struct {
+	Query       string   `json:"query"`
+	Repo        string   `json:"repo"`
+	Path        string   `json:"path"`
+	FileURL     string   `json:"file_url"`
+	Fragment    string   `json:"fragment"`
+	Match       string   `json:"match"`
+	Mode        string   `json:"mode"`
+	Rule        string   `json:"rule"`
+	Page        int      `json:"page"`
+	Fingerprint string   `json:"fingerprint"`
+	Severity    string   `json:"severity,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Owner é o dono do arquivo (CODEOWNERS ou último autor), com -owners.
+	Owner string `json:"owner,omitempty"`
+	// Language e Stars descrevem o repositório, com -api graphql.
+	Language string `json:"language,omitempty"`
+	Stars    int    `json:"stars,omitempty"`
+	// Groups traz os grupos nomeados da regex de filtro, um campo por grupo.
+	Groups map[string]string `json:"groups,omitempty"`
+	// Context traz as linhas do trecho ao redor da ocorrência (-C).
+	Context string `json:"context,omitempty"`
+
+	// Posição da ocorrência no trecho: linha (a partir de 1) e intervalo em bytes.
+	Line  int `json:"line"`
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Campos aceitos por -fields, na ordem em que costumam ser usados.
+var findingFields = map[string]func(Finding) string{
+	"url":      func(f Finding) string { return f.FileURL },
+	"repo":     func(f Finding) string { return f.Repo },
+	"path":     func(f Finding) string { return f.Path },
+	"match":    func(f Finding) string { return f.Match },
+	"rule":     func(f Finding) string { return f.Rule },
+	"mode":     func(f Finding) string { return f.Mode },
+	"query":    func(f Finding) string { return f.Query },
+	"line":     func(f Finding) string { return strconv.Itoa(f.Line) },
+	"start":    func(f Finding) string { return strconv.Itoa(f.Start) },
+	"end":      func(f Finding) string { return strconv.Itoa(f.End) },
+	"severity": func(f Finding) string { return f.Severity },
+	"tags":     func(f Finding) string { return strings.Join(f.Tags, ",") },
+	"owner":    func(f Finding) string { return f.Owner },
+	"language": func(f Finding) string { return f.Language },
+	"stars":    func(f Finding) string { return strconv.Itoa(f.Stars) },
+	"page":     func(f Finding) string { return strconv.Itoa(f.Page) },
+	"fragment": func(f Finding) string { return f.Fragment },
+}
+
+// fieldValue devolve o valor de um campo de -fields; nomes que não são campos
+// fixos referem-se a grupos nomeados da regex de filtro.
+func fieldValue(f Finding, name string) string {
+	if get := findingFields[name]; get != nil {
+		return get(f)
+	}
+	return f.Groups[name]
+}
+
+// parseFields valida a lista de campos informada em -fields, aceitando também
+// os nomes dos grupos nomeados da regex de filtro.
+func parseFields(list string, groups []string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if findingFields[name] == nil && !contains(groups, name) {
+			return nil, fmt.Errorf("campo desconhecido %q", name)
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// templateFuncs são as funções disponíveis nos templates de -format, além das
+// nativas do text/template (printf, len, index...).
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// parseTemplate compila o template de -format, que recebe um Finding por
+// resultado. Ele é testado com um resultado vazio para que campos
+// inexistentes falhem antes da busca.
+func parseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(io.Discard, Finding{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Chaves de deduplicação aceitas por -dedupe-by.
+var dedupeKeys = map[string]bool{
+	"match":       true,
+	"match+file":  true,
+	"match+repo":  true,
+	"fingerprint": true,
+}
+
+// dedupeKey monta a chave usada para decidir se um resultado já foi exibido.
+func dedupeKey(f Finding, by string) string {
+	switch by {
+	case "match+file":
+		return f.Match + "\x00" + f.FileURL
+	case "match+repo":
+		return f.Match + "\x00" + f.Repo
+	case "fingerprint":
+		return fingerprint(f)
+	default:
+		return f.Match
+	}
+}
+
+// Ordenações aceitas por -sort-output.
+var sortOrders = map[string]bool{
+	"alpha": true,
+	"count": true,
+	"repo":  true,
+}
+
+// fingerprint identifica um resultado de forma estável entre execuções: combina
+// modo, valor, repositório e caminho do arquivo, ignorando o commit presente na URL.
+func fingerprint(f Finding) string {
+	sum := sha256.Sum256([]byte(f.Mode + "\x00" + f.Match + "\x00" + f.Repo + "\x00" + f.Path))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// printer escreve os resultados na saída (padrão ou arquivo), aplicando a
+// deduplicação. Quando há ordenação, os resultados ficam em memória até o flush.
+type printer struct {
+	w io.Writer
+	// Cores ANSI só fazem sentido no terminal, nunca em arquivos.
+	color    bool
+	silent   bool
+	unique   bool
+	dedupeBy string
+	sortBy   string
+	top      int
+	fields   []string
+	// format é o template de -format, aplicado a cada resultado.
+	format *template.Template
+	// print0 separa os resultados com NUL em vez de quebra de linha (-print0).
+	print0 bool
+	// labelModes identifica o modo (tipo) de cada resultado quando -m tem
+	// vários modos, na saída em texto e na deduplicação.
+	labelModes bool
+	// context é a quantidade de linhas exibidas antes e depois da ocorrência.
+	context int
+	// jsonl troca o layout em texto por um objeto JSON por linha.
+	jsonl *jsonlWriter
+	// export acumula os resultados para gravar um documento completo no flush.
+	export exporter
+	// tee recebe todos os resultados em JSONL, independente da saída principal.
+	tee *jsonlWriter
+	// report acumula os resultados para o relatório HTML de -report.
+	report *htmlReport
+	// Limites de resultados por arquivo e por repositório (0 = sem limite).
+	maxPerFile int
+	maxPerRepo int
+	perFile    map[string]int
+	perRepo    map[string]int
+	seen       map[string]bool
+	counts     map[string]int
+	buffer     []Finding
+	// record monta o resultado em texto antes de ir para w.
+	record bytes.Buffer
+}
+
+func newPrinter(silent, unique bool, dedupeBy, sortBy string, top int) *printer {
+	return &printer{
+		w:      os.Stdout,
+		color:  true,
+		silent: silent,
+		// O modo silent sempre garante resultados únicos.
+		unique:   unique || silent,
+		dedupeBy: dedupeBy,
+		sortBy:   sortBy,
+		top:      top,
+		seen:     make(map[string]bool),
+		counts:   make(map[string]int),
+		perFile:  make(map[string]int),
+		perRepo:  make(map[string]int),
+	}
+}
+
+func (p *printer) emit(f Finding) {
+	// Os limites vêm primeiro para que um único arquivo ou repositório enorme
+	// não domine nem a saída nem as contagens.
+	if p.maxPerFile > 0 && p.perFile[f.FileURL] >= p.maxPerFile {
+		return
+	}
+	if p.maxPerRepo > 0 && p.perRepo[f.Repo] >= p.maxPerRepo {
+		return
+	}
+	p.perFile[f.FileURL]++
+	p.perRepo[f.Repo]++
+	f.Fingerprint = fingerprint(f)
+	f.Line = strings.Count(f.Fragment[:f.Start], "\n") + 1
+	if p.context > 0 {
+		from, to := contextWindow(f.Fragment, f.Start, f.End, p.context)
+		f.Context = f.Fragment[from:to]
+	}
+
+	// A contagem considera todas as ocorrências, mesmo as descartadas como duplicadas.
+	p.counts[f.Match]++
+	if p.unique {
+		key := dedupeKey(f, p.dedupeBy)
+		if p.labelModes {
+			// O mesmo valor em modos diferentes (domínio e subdomínio) são dois resultados.
+			key = f.Mode + "\x00" + key
+		}
+		if p.seen[key] {
+			return
+		}
+		p.seen[key] = true
+	}
+	if p.tee != nil {
+		if err := p.tee.write(f); err != nil {
+			fatalf("Erro ao gravar arquivo de -tee: %v", err)
+		}
+	}
+	if p.report != nil {
+		p.report.add(f)
+	}
+	if p.top > 0 {
+		// No modo -top apenas a contagem interessa; a saída sai no flush.
+		return
+	}
+	if p.sortBy != "" {
+		p.buffer = append(p.buffer, f)
+		return
+	}
+	p.write(f)
+}
+
+// structured indica se a saída é um formato para máquinas (JSONL, -export ou
+// -print0), que não pode receber mensagens misturadas aos resultados.
+func (p *printer) structured() bool {
+	return p.jsonl != nil || p.export != nil || p.print0
+}
+
+// endRecord encerra um resultado: quebra de linha, ou NUL com -print0.
+func (p *printer) endRecord() {
+	if p.print0 {
+		p.w.Write([]byte{0})
+		return
+	}
+	fmt.Fprintln(p.w)
+}
+
+// flush ordena e escreve os resultados mantidos em memória.
+func (p *printer) flush() {
+	if p.top > 0 {
+		p.writeTop()
+		return
+	}
+	switch p.sortBy {
+	case "alpha":
+		sort.SliceStable(p.buffer, func(i, j int) bool {
+			return p.buffer[i].Match < p.buffer[j].Match
+		})
+	case "count":
+		sort.SliceStable(p.buffer, func(i, j int) bool {
+			ci, cj := p.counts[p.buffer[i].Match], p.counts[p.buffer[j].Match]
+			if ci != cj {
+				return ci > cj
+			}
+			return p.buffer[i].Match < p.buffer[j].Match
+		})
+	case "repo":
+		sort.SliceStable(p.buffer, func(i, j int) bool {
+			return p.buffer[i].Repo < p.buffer[j].Repo
+		})
+	}
+	for _, f := range p.buffer {
+		p.write(f)
+	}
+	p.buffer = nil
+	if p.export != nil {
+		if err := p.export.write(p.w); err != nil {
+			fatalf("Erro ao gravar exportação: %v", err)
+		}
+	}
+}
+
+// tagLabel monta o sufixo " [tag1,tag2]" exibido após o valor, em vermelho no terminal.
+func (p *printer) tagLabel(f Finding) string {
+	if len(f.Tags) == 0 {
+		return ""
+	}
+	label := " [" + strings.Join(f.Tags, ",") + "]"
+	if p.color {
+		return "\033[1;31m" + label + "\033[0m"
+	}
+	return label
+}
+
+// modeLabel monta o prefixo "[modo] " que identifica o tipo do resultado
+// quando -m tem vários modos, em amarelo no terminal.
+func (p *printer) modeLabel(f Finding) string {
+	if !p.labelModes {
+		return ""
+	}
+	if p.color {
+		return "\033[33m[" + f.Mode + "]\033[0m "
+	}
+	return "[" + f.Mode + "] "
+}
+
+// contextWindow devolve o intervalo do trecho que cobre as linhas da
+// ocorrência mais n linhas antes e n depois.
+func contextWindow(fragment string, start, end, n int) (int, int) {
+	from := start
+	for i := 0; i <= n && from > 0; i++ {
+		if i > 0 {
+			from-- // pula o \n da linha anterior
+		}
+		from = strings.LastIndexByte(fragment[:from], '\n') + 1
+	}
+	to := end
+	for i := 0; i <= n && to < len(fragment); i++ {
+		if i > 0 {
+			to++ // pula o \n da linha atual
+		}
+		if j := strings.IndexByte(fragment[to:], '\n'); j >= 0 {
+			to += j
+		} else {
+			to = len(fragment)
+		}
+	}
+	// A quebra de linha no fim do trecho não abre outra linha de contexto.
+	if to == len(fragment) && to > end && strings.HasSuffix(fragment, "\n") {
+		to--
+	}
+	return from, to
+}
+
+// writeContext exibe as linhas de contexto indentadas, destacando a ocorrência.
+func (p *printer) writeContext(f Finding) {
+	from, _ := contextWindow(f.Fragment, f.Start, f.End, p.context)
+	ctx := f.Context
+	if p.color {
+		start, end := f.Start-from, f.End-from
+		ctx = ctx[:start] + "\033[1;31m" + ctx[start:end] + "\033[0m" + ctx[end:]
+	}
+	for _, line := range strings.Split(ctx, "\n") {
+		fmt.Fprintf(p.w, "    %s\n", line)
+	}
+}
+
+// writeTop exibe os N valores mais frequentes com suas contagens.
+func (p *printer) writeTop() {
+	values := make([]string, 0, len(p.counts))
+	for v := range p.counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		ci, cj := p.counts[values[i]], p.counts[values[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return values[i] < values[j]
+	})
+	if len(values) > p.top {
+		values = values[:p.top]
+	}
+	for _, v := range values {
+		if p.jsonl != nil {
+			p.jsonl.write(struct {
+				Value string `json:"value"`
+				Count int    `json:"count"`
+			}{v, p.counts[v]})
+			continue
+		}
+		switch {
+		case p.silent:
+			fmt.Fprintf(p.w, "%d\t%s\n", p.counts[v], v)
+		case p.color:
+			fmt.Fprintf(p.w, "\033[33m%6d\033[0m  \033[32m%s\033[0m\n", p.counts[v], v)
+		default:
+			fmt.Fprintf(p.w, "%6d  %s\n", p.counts[v], v)
+		}
+	}
+}
+
+func (p *printer) write(f Finding) {
+	if p.export != nil {
+		p.export.add(f)
+		return
+	}
+	if p.jsonl != nil {
+		if err := p.jsonl.write(f); err != nil {
+			fatalf("Erro ao gravar resultado: %v", err)
+		}
+		return
+	}
+	// O resultado vai para a saída numa única escrita, mesmo com -fields,
+	// -format ou -C, que o montam em partes: a rotação (-rotate-size) só
+	// troca de arquivo entre um resultado e outro.
+	out := p.w
+	p.record.Reset()
+	p.w = &p.record
+	p.writeText(f)
+	p.w = out
+	if _, err := out.Write(p.record.Bytes()); err != nil {
+		fatalf("Erro ao gravar resultado: %v", err)
+	}
+}
+
+// writeText escreve o resultado no layout em texto (-fields, -format,
+// -print0 ou o padrão).
+func (p *printer) writeText(f Finding) {
+	if p.format != nil {
+		if err := p.format.Execute(p.w, f); err != nil {
+			fatalf("Erro ao aplicar -format: %v", err)
+		}
+		p.endRecord()
+		return
+	}
+	if len(p.fields) > 0 {
+		values := make([]string, len(p.fields))
+		for i, name := range p.fields {
+			values[i] = fieldValue(f, name)
+		}
+		fmt.Fprint(p.w, strings.Join(values, "\t"))
+		p.endRecord()
+		return
+	}
+	switch {
+	case p.print0:
+		// Só o valor, para xargs -0 receber um argumento por resultado.
+		fmt.Fprint(p.w, f.Match)
+		p.endRecord()
+		return
+	case p.silent && p.labelModes:
+		fmt.Fprintf(p.w, "%s\t%s\n", f.Mode, f.Match)
+		return
+	case p.silent:
+		fmt.Fprintln(p.w, f.Match)
+		return
+	case p.color:
+		fmt.Fprintf(p.w, "%s\033[34m%s\033[0m - \033[32m%s\033[0m%s\n", p.modeLabel(f), f.FileURL, f.Match, p.tagLabel(f))
+	default:
+		fmt.Fprintf(p.w, "%s%s - %s%s\n", p.modeLabel(f), f.FileURL, f.Match, p.tagLabel(f))
+	}
+	if f.Context != "" {
+		p.writeContext(f)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
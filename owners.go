@@ -0,0 +1,136 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// codeownersPaths são os locais onde o GitHub procura o CODEOWNERS, em ordem.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule é uma linha do CODEOWNERS: um padrão de caminho e seus donos.
+type codeownersRule struct {
+	re     *regexp.Regexp
+	owners []string
+}
+
+// parseCodeowners lê um CODEOWNERS, ignorando comentários e linhas inválidas.
+func parseCodeowners(data string) []codeownersRule {
+	var rules []codeownersRule
+	for _, line := range strings.Split(data, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := regexp.Compile(codeownersPattern(fields[0]))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeownersRule{re: re, owners: fields[1:]})
+	}
+	return rules
+}
+
+// codeownersPattern converte um padrão no estilo gitignore em regex. Padrões
+// com barra no início ou no meio são ancorados na raiz do repositório; os
+// demais casam em qualquer nível. Um padrão casa também com o que está abaixo
+// dele, como um diretório.
+func codeownersPattern(p string) string {
+	dir := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if dir {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return b.String()
+}
+
+// codeownersFor devolve os donos de um caminho; como no GitHub, vale a última
+// regra que casa.
+func codeownersFor(rules []codeownersRule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].re.MatchString(path) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// ownerResolver atribui cada arquivo a um dono: os donos do CODEOWNERS do
+// repositório ou, sem regra, o autor do último commit no arquivo. As consultas
+// à API ficam em cache por repositório e por arquivo.
+type ownerResolver struct {
+	client     *githubClient
+	codeowners map[string][]codeownersRule
+	files      map[string]string
+}
+
+func newOwnerResolver(client *githubClient) *ownerResolver {
+	return &ownerResolver{
+		client:     client,
+		codeowners: make(map[string][]codeownersRule),
+		files:      make(map[string]string),
+	}
+}
+
+// resolve devolve os donos do arquivo separados por vírgula, ou "" se nenhum
+// for encontrado.
+func (r *ownerResolver) resolve(repo, path string) (string, error) {
+	key := repo + "\x00" + path
+	if owner, ok := r.files[key]; ok {
+		return owner, nil
+	}
+	rules, ok := r.codeowners[repo]
+	if !ok {
+		for _, p := range codeownersPaths {
+			data, err := r.client.fileContent(repo, p)
+			if err != nil {
+				return "", err
+			}
+			if data != nil {
+				rules = parseCodeowners(string(data))
+				break
+			}
+		}
+		r.codeowners[repo] = rules
+	}
+	owner := strings.Join(codeownersFor(rules, path), ",")
+	if owner == "" {
+		committer, err := r.client.lastCommitter(repo, path)
+		if err != nil {
+			return "", err
+		}
+		owner = committer
+	}
+	r.files[key] = owner
+	return owner, nil
+}
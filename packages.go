@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// packageRegexes encontram nomes de pacotes por ecossistema: dependências do
+// package.json, require/import no JavaScript, linhas de requirements.txt com
+// versão e declarações gem do Gemfile. O nome fica no grupo 1.
+var packageRegexes = []struct {
+	ecosystem string
+	re        *regexp.Regexp
+}{
+	{"npm", regexp.MustCompile(`"(@?[a-z0-9][a-z0-9._\-]*(?:/[a-z0-9][a-z0-9._\-]*)?)"\s*:\s*"(?:[~^<>=]*\s*v?[0-9]|\*|latest)`)},
+	{"npm", regexp.MustCompile(`\brequire\(\s*['"]([^'"./\s][^'"\s]*)['"]\s*\)`)},
+	{"npm", regexp.MustCompile(`(?m)(?:^|[;\s])(?:import|export)\b[^'"\n]*?['"]([^'"./\s][^'"\s]*)['"]`)},
+	{"pypi", regexp.MustCompile(`(?m)^[ \t]*([A-Za-z0-9][A-Za-z0-9._\-]*)(?:\[[^\]\n]*\])?[ \t]*(?:==|>=|<=|~=|!=)[ \t]*[0-9]`)},
+	{"rubygems", regexp.MustCompile(`(?m)^[ \t]*gem[ \t]+['"]([A-Za-z0-9][A-Za-z0-9._\-]*)['"]`)},
+}
+
+// packageJSONKeys são chaves do package.json com valor de versão que não são
+// dependências (version, engines).
+var packageJSONKeys = []string{"version", "node", "npm", "yarn", "pnpm"}
+
+// nodeBuiltins são os módulos nativos do Node, que não vêm do registro.
+var nodeBuiltins = []string{"assert", "buffer", "child_process", "cluster", "crypto", "dgram", "dns", "events", "fs", "http", "http2", "https", "net", "os", "path", "perf_hooks", "process", "querystring", "readline", "stream", "string_decoder", "timers", "tls", "tty", "url", "util", "v8", "vm", "worker_threads", "zlib"}
+
+// npmPackageName reduz um caminho importado ao nome do pacote:
+// lodash/fp → lodash, @acme/ui/button → @acme/ui.
+func npmPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	if strings.HasPrefix(path, "@") && len(parts) > 1 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+// findPackages extrai nomes de pacotes npm, PyPI e RubyGems, com o
+// ecossistema como tag, para caçar candidatos a dependency confusion.
+func findPackages(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, p := range packageRegexes {
+		for _, m := range p.re.FindAllStringSubmatchIndex(fragment, -1) {
+			start, end := m[2], m[3]
+			name := fragment[start:end]
+			if p.ecosystem == "npm" {
+				if strings.HasPrefix(name, "node:") || strings.ContainsAny(name, "${}") {
+					continue
+				}
+				name = npmPackageName(name)
+				if contains(nodeBuiltins, name) || contains(packageJSONKeys, name) {
+					continue
+				}
+				end = start + len(name)
+			}
+			found = append(found, extraction{Value: name, Start: start, End: end, Tags: []string{p.ecosystem}})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// packageRegistries são as URLs de consulta de cada ecossistema; %s é o nome
+// do pacote já escapado.
+var packageRegistries = map[string]string{
+	"npm":      "https://registry.npmjs.org/%s",
+	"pypi":     "https://pypi.org/pypi/%s/json",
+	"rubygems": "https://rubygems.org/api/v1/gems/%s.json",
+}
+
+// registryChecker consulta o registro público de cada pacote (-check-registry)
+// e guarda as respostas, já que o mesmo nome aparece em muitos trechos. As
+// falhas também ficam guardadas: um pacote que o registro recusou (429, por
+// exemplo) não é consultado nem relatado de novo a cada ocorrência.
+type registryChecker struct {
+	cache  map[string]bool
+	failed map[string]bool
+	// client fornece o log de auditoria e as novas tentativas (-retries).
+	client *githubClient
+}
+
+func newRegistryChecker(client *githubClient) *registryChecker {
+	return &registryChecker{cache: make(map[string]bool), failed: make(map[string]bool), client: client}
+}
+
+// unclaimed indica se o pacote não existe no registro público (404). Um
+// pacote cuja consulta já falhou conta como existente, sem novo erro.
+func (r *registryChecker) unclaimed(ecosystem, name string) (bool, error) {
+	key := ecosystem + "\x00" + name
+	if v, ok := r.cache[key]; ok {
+		return v, nil
+	}
+	if r.failed[key] {
+		return false, nil
+	}
+	endpoint, ok := packageRegistries[ecosystem]
+	if !ok {
+		return false, nil
+	}
+	// O npm espera o escopo junto do nome, com a barra escapada (@acme%2fui).
+	req, err := http.NewRequest("GET", fmt.Sprintf(endpoint, url.PathEscape(name)), nil)
+	if err != nil {
+		return false, fmt.Errorf("criar requisição: %w", err)
+	}
+	status, _, _, err := r.client.fetchRetry(req)
+	switch {
+	case err != nil:
+	case status == http.StatusOK:
+		r.cache[key] = false
+	case status == http.StatusNotFound:
+		r.cache[key] = true
+	default:
+		err = fmt.Errorf("registro %s retornou status %d para %s", ecosystem, status, name)
+	}
+	if err != nil {
+		r.failed[key] = true
+		return false, err
+	}
+	return r.cache[key], nil
+}
+
+// mark consulta o registro para cada pacote extraído e destaca os que não
+// existem, com a tag unclaimed e severidade high: qualquer um poderia
+// publicá-los com esse nome. Uma falha na consulta não impede a dos demais
+// pacotes; a primeira é devolvida no final.
+func (r *registryChecker) mark(values []extraction) error {
+	var first error
+	for i := range values {
+		if len(values[i].Tags) == 0 {
+			continue
+		}
+		missing, err := r.unclaimed(values[i].Tags[0], values[i].Value)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		if missing {
+			values[i].Severity = "high"
+			values[i].Tags = append(values[i].Tags, "unclaimed")
+		}
+	}
+	return first
+}
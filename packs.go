@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patternPack é um pacote de extratores em YAML carregado com -patterns,
+// para que cada equipe mantenha suas detecções fora do binário:
+//
+//	name: acme
+//	extractors:
+//	  - name: acme-api-key
+//	    regex: '\b(acme_[0-9a-f]{32})\b'
+//	    filter: '[1-9]'
+//	    label: acme
+//	    severity: high
+type patternPack struct {
+	Name       string          `yaml:"name"`
+	Extractors []packExtractor `yaml:"extractors"`
+}
+
+// packExtractor é um extrator do pacote. O valor é o grupo 1 da regex, quando
+// existe, ou a ocorrência inteira; filter e exclude são aplicados ao valor
+// (precisa casar e não pode casar, respectivamente). Sem label, a tag é o
+// nome do pacote.
+type packExtractor struct {
+	Name     string `yaml:"name"`
+	Regex    string `yaml:"regex"`
+	Filter   string `yaml:"filter"`
+	Exclude  string `yaml:"exclude"`
+	Label    string `yaml:"label"`
+	Severity string `yaml:"severity"`
+}
+
+// customPattern é um extrator de pacote já compilado.
+type customPattern struct {
+	secretPattern
+	filter, exclude *regexp.Regexp
+}
+
+// patternPacks são os extratores de todos os pacotes. rules tem as mesmas
+// regexes, na mesma ordem, com as palavras-chave de cada uma: só rodam no
+// trecho as que podem casar nele.
+type patternPacks struct {
+	patterns []customPattern
+	rules    *ruleSet
+}
+
+// loadPatternPacks lê e compila os pacotes de -patterns. Os nomes dos
+// extratores viram a regra de cada resultado e não podem se repetir entre
+// os pacotes.
+func loadPatternPacks(paths []string, ignoreCase bool) (*patternPacks, error) {
+	compile := func(pattern string) (*regexp.Regexp, error) {
+		if pattern == "" {
+			return nil, nil
+		}
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		return regexp.Compile(pattern)
+	}
+	packs := &patternPacks{}
+	var rules []rule
+	seen := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var pack patternPack
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&pack); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(pack.Extractors) == 0 {
+			return nil, fmt.Errorf("%s: nenhum extrator em extractors", path)
+		}
+		for i, x := range pack.Extractors {
+			if x.Name == "" || x.Regex == "" {
+				return nil, fmt.Errorf("%s: o extrator %d precisa de name e regex", path, i+1)
+			}
+			if other, ok := seen[x.Name]; ok {
+				return nil, fmt.Errorf("%s: extrator %q já definido em %s", path, x.Name, other)
+			}
+			seen[x.Name] = path
+			if _, ok := severityRank[x.Severity]; x.Severity != "" && !ok {
+				return nil, fmt.Errorf("%s: severidade %q do extrator %q deve ser critical, high, medium, low ou info", path, x.Severity, x.Name)
+			}
+			p := customPattern{secretPattern: secretPattern{name: x.Name, provider: x.Label, severity: x.Severity}}
+			if p.provider == "" {
+				p.provider = pack.Name
+			}
+			if p.re, err = compile(x.Regex); err == nil {
+				if p.filter, err = compile(x.Filter); err == nil {
+					p.exclude, err = compile(x.Exclude)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: extrator %q: %w", path, x.Name, err)
+			}
+			packs.patterns = append(packs.patterns, p)
+			rules = append(rules, rule{name: x.Name, re: re2Matcher{p.re}, keywords: regexKeywords(x.Regex)})
+		}
+	}
+	packs.rules = newRuleSet(rules)
+	return packs, nil
+}
+
+// findCustomPatterns aplica os extratores dos pacotes de -patterns ao trecho.
+func findCustomPatterns(e *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, i := range e.packs.rules.candidates(fragment) {
+		p := e.packs.patterns[i]
+		for _, x := range matchPatterns([]secretPattern{p.secretPattern}, fragment) {
+			if p.filter != nil && !p.filter.MatchString(x.Value) {
+				continue
+			}
+			if p.exclude != nil && p.exclude.MatchString(x.Value) {
+				continue
+			}
+			if p.provider == "" {
+				x.Tags = nil
+			}
+			found = append(found, x)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
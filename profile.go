@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// outputProfiles são os formatos de -of, que imitam a saída de ferramentas de
+// recon para o gfinder entrar em scripts existentes sem ajustes.
+var outputProfiles = map[string]bool{
+	"subfinder":      true,
+	"subfinder-json": true,
+	"amass":          true,
+}
+
+// profileHost é um host encontrado e os repositórios onde ele apareceu.
+type profileHost struct {
+	name  string
+	repos []string
+}
+
+// profileExporter acumula os hosts únicos e os grava no formato do perfil.
+// Com sources, cada host leva os repositórios em que foi encontrado.
+type profileExporter struct {
+	profile string
+	sources bool
+	list    []*profileHost
+	index   map[string]*profileHost
+}
+
+func newProfileExporter(profile string, sources bool) *profileExporter {
+	return &profileExporter{profile: profile, sources: sources, index: make(map[string]*profileHost)}
+}
+
+// hostOf extrai o nome de host de um resultado; IPs e textos são ignorados,
+// já que as ferramentas de subdomínios só trabalham com nomes.
+func hostOf(f Finding) string {
+	kind, value := classify(f)
+	switch kind {
+	case "domain":
+		return value
+	case "url":
+		if strings.HasPrefix(value, "//") {
+			value = "https:" + value
+		}
+		u, err := url.Parse(value)
+		if err != nil || net.ParseIP(u.Hostname()) != nil {
+			return ""
+		}
+		return strings.ToLower(u.Hostname())
+	}
+	return ""
+}
+
+func (e *profileExporter) add(f Finding) {
+	name := hostOf(f)
+	if name == "" {
+		return
+	}
+	h := e.index[name]
+	if h == nil {
+		h = &profileHost{name: name}
+		e.index[name] = h
+		e.list = append(e.list, h)
+	}
+	if !contains(h.repos, f.Repo) {
+		h.repos = append(h.repos, f.Repo)
+	}
+}
+
+func (e *profileExporter) write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, h := range e.list {
+		var err error
+		switch e.profile {
+		case "subfinder-json":
+			// Mesmo formato de subfinder -oJ: input é o domínio raiz do host.
+			line := map[string]any{"host": h.name, "input": registrableDomain(h.name, ""), "source": "github"}
+			if e.sources {
+				line["sources"] = h.repos
+			}
+			err = enc.Encode(line)
+		case "amass":
+			if e.sources {
+				_, err = fmt.Fprintf(w, "[%s] %s\n", strings.Join(h.repos, ","), h.name)
+			} else {
+				_, err = fmt.Fprintln(w, h.name)
+			}
+		default:
+			// subfinder -cs anota como host,[fonte1,fonte2].
+			if e.sources {
+				_, err = fmt.Fprintf(w, "%s,[%s]\n", h.name, strings.Join(h.repos, ","))
+			} else {
+				_, err = fmt.Fprintln(w, h.name)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// providers são os serviços de busca de código aceitos em -provider. Os que
+// não são o GitHub convertem os resultados para CodeSearchResult, para que a
+// extração, a saída e o -replay não mudem.
+var providers = []string{"github", "gitlab", "bitbucket", "gitea", "sourcegraph", "grepapp", "searchcode"}
+
+// fetchJSON faz a requisição a um provedor que não é a API REST do GitHub e
+// decodifica o JSON da resposta em v, com as novas tentativas de fetchRetry.
+func (c *githubClient) fetchJSON(req *http.Request, v any) (http.Header, error) {
+	status, header, body, err := c.fetchRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%s retornou status %d: %s", req.URL.Host, status, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, fmt.Errorf("decodificar JSON: %w", err)
+	}
+	return header, nil
+}
+
+// fetchRetry faz a requisição a um serviço que não é a API REST do GitHub,
+// passando pelo log de auditoria; falhas transitórias e o 429 são repetidos
+// até c.retries vezes, respeitando o Retry-After. Devolve a última resposta,
+// qualquer que seja o status.
+func (c *githubClient) fetchRetry(req *http.Request) (int, http.Header, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		// O corpo de um POST é consumido a cada envio.
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			req.Body = body
+		}
+		status, header, body, err := c.fetch(req)
+		limited := err == nil && status == http.StatusTooManyRequests
+		if (limited || isTransient(status, err)) && attempt < c.retries {
+			wait := jitter(c.retryWait << attempt)
+			if limited {
+				wait = retryAfter(header, wait)
+			}
+			log.Printf("Falha transitória (%s); tentativa %d de %d em %s", describeFailure(status, err), attempt+1, c.retries, wait.Round(time.Millisecond))
+			time.Sleep(wait)
+			continue
+		}
+		return status, header, body, err
+	}
+}
+
+// graphqlError é um erro da resposta GraphQL. Path indica o campo (ou
+// alias) que falhou; os demais campos de data continuam válidos.
+type graphqlError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Path    []any  `json:"path"`
+}
+
+// graphQL envia a consulta a uma API GraphQL e decodifica o campo data da
+// resposta em v. Erros de campos isolados (um repositório que não existe
+// mais, por exemplo) vêm com status 200 ao lado do restante de data e são
+// devolvidos para quem chamou decidir; só sem data a consulta falha.
+func (c *githubClient) graphQL(endpoint string, header http.Header, query string, variables map[string]any, v any) ([]graphqlError, error) {
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	if _, err := c.fetchJSON(req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 || string(resp.Data) == "null" {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return nil, fmt.Errorf("%s retornou erro: %s", req.URL.Host, strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		return nil, fmt.Errorf("decodificar JSON: %w", err)
+	}
+	return resp.Errors, nil
+}
+
+// fetch faz uma única tentativa da requisição.
+func (c *githubClient) fetch(req *http.Request) (int, http.Header, []byte, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("requisição: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("ler resposta: %w", err)
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+// record grava a página já convertida para o formato da API do GitHub, que é
+// o que -replay lê, quando -record está ativo.
+func (c *githubClient) record(query string, page int, result *CodeSearchResult) error {
+	if c.recorder == nil {
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if err := c.recorder.save(query, page, data); err != nil {
+		return fmt.Errorf("gravar resposta (-record): %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import "strings"
+
+// Marcações usadas para "desarmar" indicadores em relatórios e issues
+// (hxxp://example[.]com, 1.2.3[.]4) e o texto que cada uma representa.
+var refangTokens = []struct {
+	token, value string
+}{
+	{"hxxps", "https"},
+	{"hxxp", "http"},
+	{"[://]", "://"},
+	{"[.]", "."},
+	{"(.)", "."},
+	{"{.}", "."},
+	{"[dot]", "."},
+	{"(dot)", "."},
+	{"[:]", ":"},
+	{"[/]", "/"},
+	{"[@]", "@"},
+}
+
+// refangFragment "rearma" indicadores desarmados no trecho. Assim como em
+// unescapeFragment, devolve a posição original de cada byte do resultado.
+func refangFragment(s string) (string, []int) {
+	if !strings.Contains(strings.ToLower(s), "hxxp") && !strings.ContainsAny(s, "[({") {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	offsets := make([]int, 0, len(s)+1)
+
+	for i := 0; i < len(s); {
+		replaced := false
+		for _, t := range refangTokens {
+			// Compara no próprio s: strings.ToLower pode mudar o tamanho em
+			// bytes (o sinal de Kelvin vira k) e desalinhar as posições.
+			if n := len(t.token); i+n <= len(s) && strings.EqualFold(s[i:i+n], t.token) {
+				b.WriteString(t.value)
+				for range t.value {
+					offsets = append(offsets, i)
+				}
+				i += len(t.token)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			b.WriteByte(s[i])
+			offsets = append(offsets, i)
+			i++
+		}
+	}
+	offsets = append(offsets, len(s))
+	return b.String(), offsets
+}
+
+// composeOffsets combina dois mapeamentos de posições: inner leva do texto
+// final ao intermediário e outer do intermediário ao original.
+func composeOffsets(outer, inner []int) []int {
+	if outer == nil {
+		return inner
+	}
+	if inner == nil {
+		return outer
+	}
+	composed := make([]int, len(inner))
+	for i, at := range inner {
+		composed[i] = outer[at]
+	}
+	return composed
+}
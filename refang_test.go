@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestRefangFragment(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{"sem marcação", "nada aqui", "nada aqui"},
+		{"hxxp e [.]", "hxxp://example[.]com/x", "http://example.com/x"},
+		{"maiúsculas", "HXXPS://Example(.)com", "https://Example.com"},
+		{"[://] e [dot]", "https[://]acme[dot]io", "https://acme.io"},
+		{"sinal de Kelvin", "temp 5\u212a [x]", "temp 5\u212a [x]"},
+		{"Kelvin antes do token", "\u212a\u212a hxxp://a[.]b", "\u212a\u212a http://a.b"},
+		{"ômega e acentos", "\u2126 ção hxxp://café[.]com", "\u2126 ção http://café.com"},
+		{"token no fim", "example[", "example["},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, offsets := refangFragment(tt.fragment)
+			if got != tt.want {
+				t.Fatalf("refangFragment(%q) = %q; quer %q", tt.fragment, got, tt.want)
+			}
+			if offsets == nil {
+				return
+			}
+			if len(offsets) != len(got)+1 {
+				t.Fatalf("%d posições para %d bytes", len(offsets), len(got))
+			}
+			// Bytes copiados sem troca apontam para o mesmo byte do original.
+			for i, at := range offsets[:len(got)] {
+				if at < 0 || at >= len(tt.fragment) {
+					t.Fatalf("posição %d fora do trecho: %d", i, at)
+				}
+			}
+			if offsets[len(got)] != len(tt.fragment) {
+				t.Errorf("posição final = %d; quer %d", offsets[len(got)], len(tt.fragment))
+			}
+		})
+	}
+}
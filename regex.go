@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dlclark/regexp2"
+)
+
+// Tempo máximo de uma busca no motor PCRE, que usa backtracking e pode
+// explodir em regexes mal escritas sobre trechos grandes.
+const pcreMatchTimeout = time.Second
+
+// regexMatch é uma ocorrência da regex, com posições em bytes e os grupos de
+// captura (o índice 0 é a ocorrência inteira; grupos que não participaram ficam vazios).
+type regexMatch struct {
+	// Rule identifica a regra que gerou a ocorrência quando há várias (-rf).
+	Rule   string
+	Text   string
+	Start  int
+	End    int
+	Groups []string
+}
+
+// matcher abstrai o motor de regex usado na regex de filtro (-r).
+type matcher interface {
+	MatchString(s string) bool
+	FindAll(s string) []regexMatch
+	// SubexpNames segue a convenção do regexp: o nome de cada grupo pelo
+	// número, com "" para o grupo 0 e para grupos sem nome.
+	SubexpNames() []string
+}
+
+// re2Matcher usa o pacote regexp da biblioteca padrão (RE2).
+type re2Matcher struct {
+	re *regexp.Regexp
+}
+
+func (m re2Matcher) MatchString(s string) bool {
+	return m.re.MatchString(s)
+}
+
+func (m re2Matcher) FindAll(s string) []regexMatch {
+	var matches []regexMatch
+	for _, loc := range m.re.FindAllStringSubmatchIndex(s, -1) {
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = s[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		matches = append(matches, regexMatch{Text: groups[0], Start: loc[0], End: loc[1], Groups: groups})
+	}
+	return matches
+}
+
+func (m re2Matcher) SubexpNames() []string {
+	return m.re.SubexpNames()
+}
+
+// pcreMatcher usa o regexp2, compatível com a sintaxe Perl/PCRE (lookahead,
+// lookbehind e backreferences), comum em regras de detecção de segredos.
+type pcreMatcher struct {
+	re *regexp2.Regexp
+}
+
+func (m pcreMatcher) MatchString(s string) bool {
+	ok, err := m.re.MatchString(s)
+	return err == nil && ok
+}
+
+func (m pcreMatcher) FindAll(s string) []regexMatch {
+	var matches []regexMatch
+	// O regexp2 trabalha com posições em runes; a tabela converte para bytes.
+	var offsets []int
+	if !isASCII(s) {
+		offsets = make([]int, 0, len(s)+1)
+		for i := range s {
+			offsets = append(offsets, i)
+		}
+		offsets = append(offsets, len(s))
+	}
+	byteOffset := func(runeIndex int) int {
+		if offsets == nil {
+			return runeIndex
+		}
+		return offsets[runeIndex]
+	}
+
+	numbers := m.re.GetGroupNumbers()
+	match, err := m.re.FindStringMatch(s)
+	for err == nil && match != nil {
+		groups := make([]string, len(numbers))
+		for i, n := range numbers {
+			if g := match.GroupByNumber(n); g != nil && len(g.Captures) > 0 {
+				groups[i] = g.String()
+			}
+		}
+		start := byteOffset(match.Index)
+		matches = append(matches, regexMatch{
+			Text:   match.String(),
+			Start:  start,
+			End:    byteOffset(match.Index + match.Length),
+			Groups: groups,
+		})
+		match, err = m.re.FindNextMatch(match)
+	}
+	return matches
+}
+
+func (m pcreMatcher) SubexpNames() []string {
+	numbers := m.re.GetGroupNumbers()
+	names := make([]string, len(numbers))
+	for i, n := range numbers {
+		// Grupos sem nome recebem o próprio número como nome no regexp2.
+		if name := m.re.GroupNameFromNumber(n); name != strconv.Itoa(n) {
+			names[i] = name
+		}
+	}
+	return names
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// namedGroups monta o mapa nome → valor dos grupos nomeados de uma ocorrência.
+func namedGroups(m matcher, rm regexMatch) map[string]string {
+	var groups map[string]string
+	for i, name := range m.SubexpNames() {
+		if name == "" || i >= len(rm.Groups) {
+			continue
+		}
+		if groups == nil {
+			groups = make(map[string]string)
+		}
+		groups[name] = rm.Groups[i]
+	}
+	return groups
+}
+
+// fixedMatcher trata os padrões como strings literais (-F), casadas todas de
+// uma vez pelo autômato de Aho-Corasick. Ocorrências sobrepostas são resolvidas
+// como no grep: a mais à esquerda e, no empate, a mais longa.
+type fixedMatcher struct {
+	ac *ahoCorasick
+}
+
+func newFixedMatcher(patterns []string, ignoreCase bool) fixedMatcher {
+	return fixedMatcher{newAhoCorasick(patterns, ignoreCase)}
+}
+
+func (m fixedMatcher) MatchString(s string) bool {
+	return m.ac.matchAny(s)
+}
+
+func (m fixedMatcher) FindAll(s string) []regexMatch {
+	var all [][2]int
+	m.ac.findAll(s, func(_, start, end int) bool {
+		all = append(all, [2]int{start, end})
+		return true
+	})
+	sort.Slice(all, func(i, j int) bool {
+		if all[i][0] != all[j][0] {
+			return all[i][0] < all[j][0]
+		}
+		return all[i][1] > all[j][1]
+	})
+
+	var matches []regexMatch
+	last := 0
+	for _, loc := range all {
+		if loc[0] < last {
+			continue
+		}
+		text := s[loc[0]:loc[1]]
+		matches = append(matches, regexMatch{Text: text, Start: loc[0], End: loc[1], Groups: []string{text}})
+		last = loc[1]
+	}
+	return matches
+}
+
+func (m fixedMatcher) SubexpNames() []string {
+	return []string{""}
+}
+
+// loadPatterns lê um arquivo de padrões (-rf), um por linha, ignorando linhas vazias.
+func loadPatterns(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("nenhum padrão em %s", path)
+	}
+	return patterns, nil
+}
+
+// hasGroup indica se a regex possui o grupo informado por número ou por nome.
+func hasGroup(m matcher, group string) bool {
+	names := m.SubexpNames()
+	if n, err := strconv.Atoi(group); err == nil {
+		return n >= 0 && n < len(names)
+	}
+	return contains(names, group)
+}
+
+// matchOptions reúne as opções de compilação da regex de filtro.
+type matchOptions struct {
+	engine     string
+	ignoreCase bool
+	fixed      bool
+	// multiline faz ^ e $ casarem no início e no fim de cada linha.
+	multiline bool
+	// dotall faz . casar também com quebras de linha.
+	dotall bool
+}
+
+// compileMatcher compila os padrões de filtro no motor escolhido em -engine.
+// Com fixed, os padrões são literais; vários padrões (de -rf) viram um
+// conjunto de regras com pré-filtro por palavras-chave.
+func compileMatcher(patterns []string, opts matchOptions) (matcher, error) {
+	if opts.fixed {
+		return newFixedMatcher(patterns, opts.ignoreCase), nil
+	}
+	if len(patterns) > 1 {
+		rules := make([]rule, len(patterns))
+		for i, p := range patterns {
+			re, err := compileRegex(p, opts)
+			if err != nil {
+				return nil, fmt.Errorf("padrão %q: %w", p, err)
+			}
+			rules[i] = rule{name: p, re: re, keywords: regexKeywords(p)}
+		}
+		return newRuleSet(rules), nil
+	}
+	return compileRegex(patterns[0], opts)
+}
+
+// compileRegex compila uma única regex no motor escolhido em -engine.
+func compileRegex(pattern string, opts matchOptions) (matcher, error) {
+	switch opts.engine {
+	case "", "re2":
+		flags := ""
+		if opts.ignoreCase {
+			flags += "i"
+		}
+		if opts.multiline {
+			flags += "m"
+		}
+		if opts.dotall {
+			flags += "s"
+		}
+		if flags != "" {
+			pattern = "(?" + flags + ")" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re2Matcher{re}, nil
+	case "pcre":
+		reOpts := regexp2.None
+		if opts.ignoreCase {
+			reOpts |= regexp2.IgnoreCase
+		}
+		if opts.multiline {
+			reOpts |= regexp2.Multiline
+		}
+		if opts.dotall {
+			reOpts |= regexp2.Singleline
+		}
+		re, err := regexp2.Compile(pattern, reOpts)
+		if err != nil {
+			return nil, err
+		}
+		re.MatchTimeout = pcreMatchTimeout
+		return pcreMatcher{re}, nil
+	}
+	return nil, fmt.Errorf("motor de regex desconhecido %q (use 're2' ou 'pcre')", opts.engine)
+}
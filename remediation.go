@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// noOwner agrupa os resultados sem dono identificado.
+const noOwner = "(sem responsável)"
+
+// severityRank ordena as severidades da mais para a menos grave.
+var severityRank = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3, "info": 4}
+
+func severityOrder(s string) int {
+	if r, ok := severityRank[s]; ok {
+		return r
+	}
+	return len(severityRank)
+}
+
+// remediationExporter gera um relatório em Markdown com os resultados
+// agrupados por responsável (-owners), para cada time corrigir o que é seu.
+type remediationExporter struct {
+	query  string
+	owners map[string][]Finding
+	seen   map[string]bool
+}
+
+func newRemediationExporter(query string) exporter {
+	return &remediationExporter{query: query, owners: make(map[string][]Finding), seen: make(map[string]bool)}
+}
+
+func (e *remediationExporter) add(f Finding) {
+	fp := fingerprint(f)
+	if e.seen[fp] {
+		return
+	}
+	e.seen[fp] = true
+	// Um arquivo com vários donos entra na seção de cada um.
+	owners := strings.Split(f.Owner, ",")
+	if f.Owner == "" {
+		owners = []string{noOwner}
+	}
+	for _, o := range owners {
+		e.owners[o] = append(e.owners[o], f)
+	}
+}
+
+func (e *remediationExporter) write(w io.Writer) error {
+	names := make([]string, 0, len(e.owners))
+	for name := range e.owners {
+		names = append(names, name)
+	}
+	// Quem tem mais pendências aparece primeiro.
+	sort.Slice(names, func(i, j int) bool {
+		a, b := e.owners[names[i]], e.owners[names[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	b.WriteString("# Relatório de remediação\n\n")
+	fmt.Fprintf(&b, "Busca: `%s` — %d ocorrências, %d responsáveis.\n", e.query, len(e.seen), len(names))
+	for _, name := range names {
+		findings := e.owners[name]
+		sort.SliceStable(findings, func(i, j int) bool {
+			a, b := findings[i], findings[j]
+			if severityOrder(a.Severity) != severityOrder(b.Severity) {
+				return severityOrder(a.Severity) < severityOrder(b.Severity)
+			}
+			if a.Repo != b.Repo {
+				return a.Repo < b.Repo
+			}
+			return a.Path < b.Path
+		})
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", name, len(findings))
+		b.WriteString("| Severidade | Arquivo | Valor |\n|---|---|---|\n")
+		for _, f := range findings {
+			severity := f.Severity
+			if severity == "" {
+				severity = "-"
+			}
+			fmt.Fprintf(&b, "| %s | [%s](%s) | `%s` |\n", severity, markdownCell(f.Repo+"/"+f.Path), f.FileURL, markdownCell(f.Match))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapa o que quebraria uma célula de tabela em Markdown.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "`", "'")
+}
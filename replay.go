@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// captureMeta descreve uma captura gravada com -record.
+type captureMeta struct {
+	Query    string    `json:"query"`
+	Recorded time.Time `json:"recorded"`
+}
+
+const captureMetaFile = "capture.json"
+
+// capturePage é o nome do arquivo com a resposta bruta de uma página.
+func capturePage(dir string, page int) string {
+	return filepath.Join(dir, fmt.Sprintf("page-%03d.json", page))
+}
+
+// recorder grava as respostas brutas da API num diretório de captura, que pode
+// ser reprocessado depois com -replay sem gastar limite de requisições.
+type recorder struct {
+	dir string
+}
+
+func newRecorder(dir string) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recorder{dir: dir}, nil
+}
+
+func (r *recorder) save(query string, page int, body []byte) error {
+	if page == 1 {
+		meta, err := json.MarshalIndent(captureMeta{Query: query, Recorded: time.Now().UTC()}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(r.dir, captureMetaFile), append(meta, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(capturePage(r.dir, page), body, 0o644)
+}
+
+// replaySource lê as páginas de um diretório de captura no lugar da API.
+type replaySource struct {
+	dir string
+}
+
+// loadCapture abre um diretório de captura e devolve a query gravada.
+func loadCapture(dir string) (*replaySource, string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, captureMetaFile))
+	if err != nil {
+		return nil, "", err
+	}
+	var meta captureMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", captureMetaFile, err)
+	}
+	return &replaySource{dir: dir}, meta.Query, nil
+}
+
+// searchCode devolve a página gravada; uma página ausente encerra a busca.
+func (r *replaySource) searchCode(_ string, page, _ int) (*CodeSearchResult, error) {
+	body, err := os.ReadFile(capturePage(r.dir, page))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &CodeSearchResult{last: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result CodeSearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(capturePage(r.dir, page)), err)
+	}
+	// A captura termina na última página gravada.
+	_, err = os.Stat(capturePage(r.dir, page+1))
+	result.last = err != nil
+	return &result, nil
+}
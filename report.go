@@ -0,0 +1,115 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"time"
+)
+
+// reportTemplate é o relatório HTML de -report: um arquivo só, com o estilo
+// embutido, para ser aberto no navegador ou entregue ao cliente.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>gfinder — {{.Query}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1100px; padding: 0 1em; color: #1f2328; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.2em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; margin-top: 2em; }
+.summary { color: #59636e; }
+.finding { border: 1px solid #d0d7de; border-radius: 6px; margin: 1em 0; }
+.finding header { background: #f6f8fa; padding: .5em .8em; border-bottom: 1px solid #d0d7de; }
+.finding header code { font-weight: bold; }
+.meta { color: #59636e; font-size: .9em; margin-left: .5em; }
+pre { margin: 0; padding: .8em; overflow-x: auto; font-size: .85em; white-space: pre-wrap; word-break: break-all; }
+mark { background: #fff8c5; outline: 1px solid #d4a72c; }
+nav ul { columns: 2; }
+</style>
+</head>
+<body>
+<h1>gfinder</h1>
+<p class="summary">Busca <code>{{.Query}}</code> — {{.Total}} ocorrências em {{len .Repos}} repositórios, gerado em {{.Generated}}.</p>
+<nav><ul>
+{{- range $i, $r := .Repos}}
+<li><a href="#repo-{{$i}}">{{$r.Name}}</a> ({{len $r.Findings}})</li>
+{{- end}}
+</ul></nav>
+{{- range $i, $r := .Repos}}
+<h2 id="repo-{{$i}}">{{$r.Name}}</h2>
+{{- range $r.Findings}}
+<div class="finding">
+<header><code>{{.Match}}</code><span class="meta">{{.Mode}}{{if .Rule}} · {{.Rule}}{{end}}{{if .Severity}} · {{.Severity}}{{end}}{{if .Owner}} · {{.Owner}}{{end}}</span><br>
+<a href="{{.FileURL}}">{{.Path}}</a><span class="meta">linha {{.Line}} do trecho</span></header>
+<pre>{{.Before}}<mark>{{.Hit}}</mark>{{.After}}</pre>
+</div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// reportFinding é um resultado do relatório, com o trecho já dividido em
+// volta da ocorrência para destacá-la.
+type reportFinding struct {
+	Finding
+	Before, Hit, After string
+}
+
+type reportRepo struct {
+	Name     string
+	Findings []reportFinding
+}
+
+// htmlReport acumula os resultados e gera o relatório HTML agrupado por
+// repositório.
+type htmlReport struct {
+	query string
+	repos map[string][]reportFinding
+	total int
+}
+
+func newHTMLReport(query string) *htmlReport {
+	return &htmlReport{query: query, repos: make(map[string][]reportFinding)}
+}
+
+func (r *htmlReport) add(f Finding) {
+	r.repos[f.Repo] = append(r.repos[f.Repo], reportFinding{
+		Finding: f,
+		Before:  f.Fragment[:f.Start],
+		Hit:     f.Fragment[f.Start:f.End],
+		After:   f.Fragment[f.End:],
+	})
+	r.total++
+}
+
+func (r *htmlReport) write(w io.Writer) error {
+	repos := make([]reportRepo, 0, len(r.repos))
+	for name, findings := range r.repos {
+		sort.SliceStable(findings, func(i, j int) bool {
+			return findings[i].Path < findings[j].Path
+		})
+		repos = append(repos, reportRepo{Name: name, Findings: findings})
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+	return reportTemplate.Execute(w, map[string]any{
+		"Query":     r.query,
+		"Total":     r.total,
+		"Repos":     repos,
+		"Generated": time.Now().Format("2006-01-02 15:04"),
+	})
+}
+
+// writeReport grava o relatório no arquivo (compactado se terminar em .gz).
+func writeReport(path string, r *htmlReport) error {
+	file, err := openOutput(path)
+	if err != nil {
+		return err
+	}
+	if err := r.write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
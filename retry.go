@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// secondaryBackoff é a primeira espera após o limite secundário; o GitHub
+	// recomenda ao menos um minuto. Dobra a cada nova tentativa.
+	secondaryBackoff = time.Minute
+	// maxSecondaryRetries limita as tentativas seguidas no limite secundário.
+	maxSecondaryRetries = 5
+)
+
+// isSecondaryLimit reconhece a resposta do limite secundário (detecção de
+// abuso): 403 ou 429 com a mensagem correspondente no corpo.
+func isSecondaryLimit(status int, body []byte) bool {
+	if status != http.StatusForbidden && status != http.StatusTooManyRequests {
+		return false
+	}
+	lower := bytes.ToLower(body)
+	return bytes.Contains(lower, []byte("secondary rate limit")) || bytes.Contains(lower, []byte("abuse"))
+}
+
+// retryAfter devolve a espera pedida no cabeçalho Retry-After (em segundos) ou,
+// sem ele, a espera padrão.
+func retryAfter(header http.Header, fallback time.Duration) time.Duration {
+	if n, err := strconv.Atoi(header.Get("Retry-After")); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+	return fallback
+}
+
+// isPrimaryLimit reconhece a resposta de limite esgotado: 403 ou 429 com
+// X-RateLimit-Remaining zerado.
+func isPrimaryLimit(status int, header http.Header) bool {
+	return (status == http.StatusForbidden || status == http.StatusTooManyRequests) &&
+		header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// isTransient indica uma falha que costuma passar sozinha: erro de rede
+// (timeout, conexão recusada ou reiniciada) ou 500, 502, 503 e 504.
+func isTransient(status int, err error) bool {
+	if err != nil {
+		return isNetworkError(err)
+	}
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isNetworkError reconhece os erros de rede que valem nova tentativa. Erros
+// que se repetiriam iguais (endereço inválido, certificado recusado, host
+// inexistente, falha ao gravar o log de auditoria) não contam.
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Timeout() {
+			return true
+		}
+		// O próprio *url.Error satisfaz net.Error; o que conta é a causa.
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func describeFailure(status int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+// jitter sorteia uma espera entre metade e o total de d, para clientes que
+// falharam juntos não tentarem de novo ao mesmo tempo.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
+
+// pause espera o tempo informado. Sem quiet, mostra uma contagem regressiva na
+// saída de erro, para execuções longas sem supervisão mostrarem que seguem vivas.
+func pause(d time.Duration, quiet bool) {
+	if quiet {
+		time.Sleep(d)
+		return
+	}
+	end := time.Now().Add(d)
+	for left := d; left > 0; left = time.Until(end) {
+		fmt.Fprintf(os.Stderr, "\rLimite da API esgotado; retomando em %s   ", (left + time.Second - 1).Truncate(time.Second))
+		time.Sleep(min(left, time.Second))
+	}
+	fmt.Fprintln(os.Stderr, "\rLimite da API renovado; retomando a busca.        ")
+}
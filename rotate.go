@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rotatingOutput grava em uma sequência de arquivos derivados do caminho de -o,
+// trocando de arquivo ao mudar o dia ou ao atingir o tamanho máximo, e apaga os
+// arquivos mais antigos além da retenção configurada.
+//
+// Nomes gerados para -o results.jsonl.gz:
+//
+//	results-2024-05-01.jsonl.gz     (rotação diária)
+//	results-2024-05-01.1.jsonl.gz   (diária + tamanho)
+//	results.1.jsonl.gz              (apenas tamanho)
+type rotatingOutput struct {
+	stem    string
+	ext     string
+	daily   bool
+	maxSize int64
+	keep    int
+
+	cur     *outputFile
+	day     string
+	seq     int
+	written int64
+}
+
+func newRotatingOutput(path string, daily bool, maxSize int64, keep int) (*rotatingOutput, error) {
+	dir, name := filepath.Split(path)
+	stem, ext := name, ""
+	if i := strings.Index(name, "."); i > 0 {
+		stem, ext = name[:i], name[i:]
+	}
+	r := &rotatingOutput{
+		stem:    filepath.Join(dir, stem),
+		ext:     ext,
+		daily:   daily,
+		maxSize: maxSize,
+		keep:    keep,
+	}
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// name monta o caminho do arquivo atual.
+func (r *rotatingOutput) name() string {
+	name := r.stem
+	if r.daily {
+		name += "-" + r.day
+	}
+	if r.seq > 0 {
+		name += "." + strconv.Itoa(r.seq)
+	}
+	return name + r.ext
+}
+
+// rotate fecha o arquivo atual (se houver) e abre o próximo da sequência.
+func (r *rotatingOutput) rotate() error {
+	today := time.Now().Format("2006-01-02")
+	if r.cur != nil {
+		if err := r.cur.Close(); err != nil {
+			return err
+		}
+		if r.daily && today != r.day {
+			r.seq = 0
+		} else {
+			r.seq++
+		}
+	}
+	r.day = today
+	// Não sobrescreve arquivos de execuções anteriores com o mesmo nome.
+	for {
+		if _, err := os.Stat(r.name()); os.IsNotExist(err) {
+			break
+		}
+		r.seq++
+	}
+
+	file, err := openOutput(r.name())
+	if err != nil {
+		return err
+	}
+	r.cur = file
+	r.written = 0
+	return r.prune()
+}
+
+// prune apaga os arquivos rotacionados mais antigos, mantendo os r.keep mais recentes.
+func (r *rotatingOutput) prune() error {
+	if r.keep <= 0 {
+		return nil
+	}
+	pattern := regexp.MustCompile("^" + regexp.QuoteMeta(filepath.Base(r.stem)) +
+		`(-\d{4}-\d{2}-\d{2})?(\.\d+)?` + regexp.QuoteMeta(r.ext) + "$")
+	candidates, err := filepath.Glob(r.stem + "*" + r.ext)
+	if err != nil {
+		return err
+	}
+
+	type rotated struct {
+		path    string
+		modTime time.Time
+	}
+	var files []rotated
+	for _, path := range candidates {
+		if !pattern.MatchString(filepath.Base(path)) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, rotated{path, info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	current := r.name()
+	kept := 0
+	for _, f := range files {
+		if f.path == current || kept < r.keep-1 {
+			if f.path != current {
+				kept++
+			}
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write troca de arquivo antes de gravar quando o dia mudou ou o tamanho foi
+// atingido. O printer grava cada resultado numa única chamada, então a troca
+// nunca corta um resultado ao meio. O tamanho considera os bytes antes da compressão.
+func (r *rotatingOutput) Write(p []byte) (int, error) {
+	dayChanged := r.daily && time.Now().Format("2006-01-02") != r.day
+	full := r.maxSize > 0 && r.written > 0 && r.written+int64(len(p)) > r.maxSize
+	if dayChanged || full {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.cur.Write(p)
+	r.written += int64(n)
+	return n, err
+}
+
+func (r *rotatingOutput) Close() error {
+	return r.cur.Close()
+}
+
+// parseSize interpreta tamanhos como "500KB", "100MB" ou "1GB" (sem sufixo, bytes).
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		value  int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.value
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("tamanho inválido %q", s)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// Palavras-chave menores que isso aparecem em quase todo trecho e não
+// servem como pré-filtro.
+const minKeywordLength = 3
+
+// rule é uma regex de detecção, com as palavras-chave que precisam aparecer
+// no trecho para que valha a pena executá-la. Sem palavras-chave, a regra
+// roda em todos os trechos.
+type rule struct {
+	name     string
+	re       matcher
+	keywords []string
+}
+
+// ruleSet executa várias regras sobre cada trecho. Um autômato de
+// Aho-Corasick com as palavras-chave de todas as regras seleciona quais
+// regexes rodar, mantendo o custo por trecho quase constante mesmo com
+// centenas de regras carregadas.
+//
+// Os grupos de captura seguem a numeração de uma alternância das regras:
+// os grupos da primeira regra vêm primeiro, depois os da segunda, etc.
+type ruleSet struct {
+	rules  []rule
+	always []int
+	ac     *ahoCorasick
+	// keywordRule mapeia cada palavra-chave do autômato para sua regra.
+	keywordRule []int
+	names       []string
+	offsets     []int
+}
+
+func newRuleSet(rules []rule) *ruleSet {
+	rs := &ruleSet{rules: rules, names: []string{""}}
+	var keywords []string
+	for i, r := range rules {
+		if len(r.keywords) == 0 {
+			rs.always = append(rs.always, i)
+		}
+		for _, k := range r.keywords {
+			keywords = append(keywords, k)
+			rs.keywordRule = append(rs.keywordRule, i)
+		}
+		rs.offsets = append(rs.offsets, len(rs.names)-1)
+		rs.names = append(rs.names, r.re.SubexpNames()[1:]...)
+	}
+	rs.ac = newAhoCorasick(keywords, true)
+	return rs
+}
+
+// candidates devolve, em ordem, os índices das regras que podem casar no trecho.
+func (rs *ruleSet) candidates(s string) []int {
+	selected := make(map[int]bool, len(rs.always))
+	for _, i := range rs.always {
+		selected[i] = true
+	}
+	rs.ac.findAll(s, func(keyword, _, _ int) bool {
+		selected[rs.keywordRule[keyword]] = true
+		return len(selected) < len(rs.rules)
+	})
+	indexes := make([]int, 0, len(selected))
+	for i := range selected {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+func (rs *ruleSet) MatchString(s string) bool {
+	for _, i := range rs.candidates(s) {
+		if rs.rules[i].re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *ruleSet) FindAll(s string) []regexMatch {
+	var matches []regexMatch
+	for _, i := range rs.candidates(s) {
+		for _, m := range rs.rules[i].re.FindAll(s) {
+			groups := make([]string, len(rs.names))
+			groups[0] = m.Text
+			copy(groups[1+rs.offsets[i]:], m.Groups[1:])
+			m.Groups = groups
+			m.Rule = rs.rules[i].name
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+func (rs *ruleSet) SubexpNames() []string {
+	return rs.names
+}
+
+// regexKeywords deriva de uma regex um conjunto de literais dos quais pelo
+// menos um precisa aparecer em qualquer texto que ela case. Devolve nil quando
+// não há um conjunto útil (a regra então roda sempre). Regexes que o parser
+// do Go não entende, como as com lookarounds do motor PCRE, também caem aqui.
+func regexKeywords(pattern string) []string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	keywords := requiredLiterals(re.Simplify())
+	for _, k := range keywords {
+		// O autômato só compara sem diferenciar maiúsculas em ASCII.
+		if len(k) < minKeywordLength || !isASCII(k) {
+			return nil
+		}
+	}
+	return keywords
+}
+
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{strings.ToLower(string(re.Rune))}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		// Fica com o filho cujo literal mais curto é o mais longo: é o que
+		// descarta mais trechos.
+		var best []string
+		bestLen := 0
+		for _, sub := range re.Sub {
+			lits := requiredLiterals(sub)
+			if l := shortest(lits); l > bestLen {
+				best, bestLen = lits, l
+			}
+		}
+		return best
+	case syntax.OpAlternate:
+		var all []string
+		for _, sub := range re.Sub {
+			lits := requiredLiterals(sub)
+			if lits == nil {
+				return nil
+			}
+			all = append(all, lits...)
+		}
+		return all
+	}
+	return nil
+}
+
+func shortest(list []string) int {
+	if len(list) == 0 {
+		return 0
+	}
+	n := len(list[0])
+	for _, s := range list[1:] {
+		if len(s) < n {
+			n = len(s)
+		}
+	}
+	return n
+}
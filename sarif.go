@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// sarifLevels traduz a severidade do resultado para o nível do SARIF.
+var sarifLevels = map[string]string{
+	"critical": "error",
+	"high":     "error",
+	"medium":   "warning",
+	"low":      "note",
+	"info":     "note",
+}
+
+// sarifFormat gera um log SARIF 2.1.0 com uma regra por modo ou padrão ativo,
+// para envio ao code scanning do GitHub ou a painéis de segurança.
+type sarifFormat struct {
+	rules   []map[string]any
+	index   map[string]int
+	results []map[string]any
+}
+
+func newSARIFFormat(io.Writer, []string) exporter {
+	return &sarifFormat{index: make(map[string]int)}
+}
+
+func (s *sarifFormat) add(f Finding) {
+	rule := f.Rule
+	if rule == "" {
+		rule = "match"
+	}
+	i, ok := s.index[rule]
+	if !ok {
+		i = len(s.rules)
+		s.index[rule] = i
+		s.rules = append(s.rules, map[string]any{
+			"id":               rule,
+			"shortDescription": map[string]any{"text": "gfinder: " + rule},
+		})
+	}
+	level, ok := sarifLevels[f.Severity]
+	if !ok {
+		level = "warning"
+	}
+	props := map[string]any{"repo": f.Repo, "file_url": f.FileURL, "match": f.Match}
+	if len(f.Tags) > 0 {
+		props["tags"] = f.Tags
+	}
+	// A API devolve só trechos, sem a linha no arquivo; a localização fica no
+	// nível do arquivo e o valor vai na mensagem.
+	s.results = append(s.results, map[string]any{
+		"ruleId":    rule,
+		"ruleIndex": i,
+		"level":     level,
+		"message":   map[string]any{"text": fmt.Sprintf("%s encontrado em %s", f.Match, f.Repo)},
+		"locations": []any{map[string]any{
+			"physicalLocation": map[string]any{
+				"artifactLocation": map[string]any{"uri": f.Path},
+			},
+		}},
+		"partialFingerprints": map[string]any{"gfinder/v1": fingerprint(f)},
+		"properties":          props,
+	})
+}
+
+func (s *sarifFormat) write(w io.Writer) error {
+	rules := s.rules
+	if rules == nil {
+		rules = []map[string]any{}
+	}
+	results := s.results
+	if results == nil {
+		results = []map[string]any{}
+	}
+	log := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool": map[string]any{"driver": map[string]any{
+				"name":           "gfinder",
+				"informationUri": "https://github.com/gilsgil/gfinder",
+				"rules":          rules,
+			}},
+			"results": results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// scope decide se um host está dentro do escopo definido em -scope e -t.
+//
+// Entradas aceitas:
+//
+//	example.com     apenas o próprio host
+//	*.example.com   qualquer subdomínio (sub.example.com, a.b.example.com);
+//	                example.com só quando includeApex estiver ativo
+//
+// A comparação é feita por rótulos inteiros: *.example.com nunca casa com
+// notexample.com nem com example.com.evil.net.
+type scope struct {
+	exact       map[string]bool
+	wildcards   []string
+	includeApex bool
+}
+
+func newScope(includeApex bool) *scope {
+	return &scope{exact: make(map[string]bool), includeApex: includeApex}
+}
+
+// add inclui uma entrada no escopo.
+func (s *scope) add(entry string) error {
+	entry = normalizeScopeHost(entry)
+	if entry == "" {
+		return nil
+	}
+	if base, ok := strings.CutPrefix(entry, "*."); ok {
+		if base == "" || strings.Contains(base, "*") {
+			return fmt.Errorf("entrada de escopo inválida %q", entry)
+		}
+		// Um curinga sobre sufixo público (*.co.uk, *.github.io) cobre domínios
+		// de terceiros e quase sempre é um engano.
+		if suffix, _ := publicsuffix.PublicSuffix(base); suffix == base {
+			log.Printf("Aviso: %q cobre um sufixo público inteiro", entry)
+		}
+		s.wildcards = append(s.wildcards, base)
+		return nil
+	}
+	if strings.Contains(entry, "*") {
+		return fmt.Errorf("curingas só são aceitos no início (*.dominio): %q", entry)
+	}
+	s.exact[entry] = true
+	return nil
+}
+
+// addTarget inclui um domínio alvo de -t, equivalente a *.dominio.
+func (s *scope) addTarget(domain string) error {
+	domain = strings.TrimPrefix(normalizeScopeHost(domain), "*.")
+	if domain == "" {
+		return nil
+	}
+	return s.add("*." + domain)
+}
+
+// load lê um arquivo de escopo, uma entrada por linha; linhas vazias e
+// iniciadas por # são ignoradas.
+func (s *scope) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := s.add(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *scope) empty() bool {
+	return len(s.exact) == 0 && len(s.wildcards) == 0
+}
+
+// match indica se o host está no escopo.
+func (s *scope) match(host string) bool {
+	host = normalizeScopeHost(host)
+	if host == "" {
+		return false
+	}
+	if s.exact[host] {
+		return true
+	}
+	for _, base := range s.wildcards {
+		if strings.HasSuffix(host, "."+base) || (s.includeApex && host == base) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeScopeHost deixa hosts e entradas de escopo na mesma forma:
+// minúsculas, ASCII (punycode) e sem ponto final.
+func normalizeScopeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if base, ok := strings.CutPrefix(host, "*."); ok {
+		return "*." + normalizeIDN(base, "ascii")
+	}
+	return normalizeIDN(host, "ascii")
+}
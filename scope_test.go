@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestScopeMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		apex    bool
+		host    string
+		want    bool
+	}{
+		{"subdomínio", []string{"*.example.com"}, false, "api.example.com", true},
+		{"subdomínio profundo", []string{"*.example.com"}, false, "a.b.example.com", true},
+		{"sufixo sem ponto", []string{"*.example.com"}, false, "notexample.com", false},
+		{"domínio como prefixo", []string{"*.example.com"}, false, "example.com.evil.net", false},
+		{"subdomínio como prefixo", []string{"*.example.com"}, false, "api.example.com.evil.net", false},
+		{"apex sem -scope-apex", []string{"*.example.com"}, false, "example.com", false},
+		{"apex com -scope-apex", []string{"*.example.com"}, true, "example.com", true},
+		{"host exato", []string{"example.com"}, false, "example.com", true},
+		{"host exato não cobre subdomínios", []string{"example.com"}, false, "api.example.com", false},
+		{"maiúsculas", []string{"*.Example.COM"}, false, "API.example.com", true},
+		{"ponto final no host", []string{"*.example.com"}, false, "api.example.com.", true},
+		{"ponto final na entrada", []string{"example.com."}, false, "example.com", true},
+		{"IDN na entrada, punycode no host", []string{"*.münchen.de"}, false, "www.xn--mnchen-3ya.de", true},
+		{"punycode na entrada, IDN no host", []string{"*.xn--mnchen-3ya.de"}, false, "www.münchen.de", true},
+		{"IDN exato", []string{"bücher.example"}, false, "xn--bcher-kva.example", true},
+		{"host vazio", []string{"*.example.com"}, false, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScope(tt.apex)
+			for _, e := range tt.entries {
+				if err := s.add(e); err != nil {
+					t.Fatalf("add(%q): %v", e, err)
+				}
+			}
+			if got := s.match(tt.host); got != tt.want {
+				t.Errorf("match(%q) com %v = %v; quer %v", tt.host, tt.entries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeAddInvalid(t *testing.T) {
+	for _, entry := range []string{"*.", "api.*.example.com", "*.*.example.com"} {
+		if err := newScope(false).add(entry); err == nil {
+			t.Errorf("add(%q) deveria falhar", entry)
+		}
+	}
+}
+
+func TestScopeAddTarget(t *testing.T) {
+	s := newScope(false)
+	if err := s.addTarget("Example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.match("api.example.com") || s.match("notexample.com") {
+		t.Errorf("-t example.com deveria equivaler a *.example.com")
+	}
+}
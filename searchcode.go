@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// searchcodeURL é a raiz do searchcode.com, que indexa código público de
+// várias forjas (GitHub, Bitbucket, GitLab, Google Code...).
+const searchcodeURL = "https://searchcode.com"
+
+// searchcodeSearcher usa a API de busca do searchcode.com (-provider
+// searchcode), que não exige autenticação. Com -org, só ficam os
+// repositórios da organização.
+type searchcodeSearcher struct {
+	org string
+	// offset são as páginas já usadas pelo provedor que rodou antes na mesma
+	// busca (-provider github,searchcode); a página 1 do searchcode vem depois
+	// delas, inclusive na gravação (-record).
+	offset int
+	// seen são os arquivos do provedor anterior, que não se repetem.
+	seen map[string]bool
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+}
+
+// searchcodeSearchResponse é a parte usada da resposta da busca. As linhas
+// encontradas vêm num mapa do número da linha para o texto.
+type searchcodeSearchResponse struct {
+	Total    int  `json:"total"`
+	NextPage *int `json:"nextpage"`
+	Results  []struct {
+		Repo     string            `json:"repo"`
+		Location string            `json:"location"`
+		Filename string            `json:"filename"`
+		URL      string            `json:"url"`
+		Lines    map[string]string `json:"lines"`
+	} `json:"results"`
+}
+
+// searchcodeRepo converte o endereço do repositório (https://github.com/acme/app
+// ou git://github.com/acme/app.git) no nome owner/repo usado pelos outros
+// provedores.
+func searchcodeRepo(repo string) string {
+	u, err := url.Parse(repo)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(repo, ".git")
+	}
+	return strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+}
+
+// searchcodeFragment junta as linhas na ordem do arquivo.
+func searchcodeFragment(lines map[string]string) string {
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		if i, err := strconv.Atoi(n); err == nil {
+			numbers = append(numbers, i)
+		}
+	}
+	sort.Ints(numbers)
+	text := make([]string, len(numbers))
+	for i, n := range numbers {
+		text[i] = lines[strconv.Itoa(n)]
+	}
+	return strings.Join(text, "\n")
+}
+
+func (s *searchcodeSearcher) searchCode(query string, page, perPage int) (*CodeSearchResult, error) {
+	// Com o GitHub na mesma busca, a query já traz o org: dele, que o
+	// searchcode não entende; a organização é filtrada abaixo.
+	q := query
+	if s.org != "" {
+		q = strings.TrimSuffix(q, " org:"+s.org)
+	}
+	for {
+		result, err := s.fetch(q, page-s.offset, perPage)
+		if err != nil {
+			return nil, err
+		}
+		// Uma página sem nada novo não encerra a busca: a próxima do
+		// searchcode fica no lugar dela.
+		if len(result.Items) == 0 && !result.last {
+			s.offset--
+			continue
+		}
+		if err := s.client.record(query, page, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// fetch busca a página do searchcode (a partir de 1) e descarta os arquivos
+// de fora de -org e os que o provedor anterior já trouxe.
+func (s *searchcodeSearcher) fetch(query string, page, perPage int) (*CodeSearchResult, error) {
+	// As páginas do searchcode começam em 0.
+	params := url.Values{"q": {query}, "p": {strconv.Itoa(page - 1)}, "per_page": {strconv.Itoa(perPage)}}
+	req, err := http.NewRequest("GET", searchcodeURL+"/api/codesearch_I/?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	var resp searchcodeSearchResponse
+	if _, err := s.client.fetchJSON(req, &resp); err != nil {
+		return nil, err
+	}
+
+	// Converte para o formato da API do GitHub, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{TotalCount: resp.Total}
+	for _, r := range resp.Results {
+		repo := searchcodeRepo(r.Repo)
+		if s.org != "" && !strings.EqualFold(strings.SplitN(repo, "/", 2)[0], s.org) {
+			continue
+		}
+		item := CodeSearchItem{
+			Path:    strings.TrimPrefix(path.Join(r.Location, r.Filename), "/"),
+			HTMLURL: r.URL,
+		}
+		item.Repository.FullName = repo
+		if s.seen[fileKey(item)] {
+			continue
+		}
+		item.TextMatches = []TextMatch{{Fragment: searchcodeFragment(r.Lines)}}
+		result.Items = append(result.Items, item)
+	}
+	result.last = resp.NextPage == nil || len(resp.Results) == 0
+	return result, nil
+}
+
+// chainedSearcher roda o searchcode depois de outro provedor
+// (-provider github,searchcode), com as páginas numeradas em sequência. Os
+// arquivos que o primeiro já trouxe (mesmo repositório e caminho) ficam em
+// seen, para o searchcode não repeti-los.
+type chainedSearcher struct {
+	first  codeSearcher
+	second *searchcodeSearcher
+	// done indica que o primeiro provedor acabou.
+	done bool
+}
+
+func newChainedSearcher(first codeSearcher, second *searchcodeSearcher) *chainedSearcher {
+	second.seen = make(map[string]bool)
+	return &chainedSearcher{first: first, second: second}
+}
+
+// fileKey identifica o arquivo sem diferenciar maiúsculas no repositório,
+// como o GitHub.
+func fileKey(item CodeSearchItem) string {
+	return strings.ToLower(item.Repository.FullName) + "\x00" + item.Path
+}
+
+func (c *chainedSearcher) searchCode(query string, page, perPage int) (*CodeSearchResult, error) {
+	if c.done {
+		return c.second.searchCode(query, page, perPage)
+	}
+	result, err := c.first.searchCode(query, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range result.Items {
+		c.second.seen[fileKey(item)] = true
+	}
+	if len(result.Items) > 0 && !result.last {
+		return result, nil
+	}
+	c.done = true
+	// Sem resultados no primeiro, esta página já vem do searchcode.
+	if len(result.Items) == 0 {
+		c.second.offset = page - 1
+		return c.second.searchCode(query, page, perPage)
+	}
+	c.second.offset = page
+	result.last = false
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// sourcegraphURL é a instância padrão de -provider sourcegraph; -base-url
+// aponta para instâncias próprias.
+const sourcegraphURL = "https://sourcegraph.com"
+
+// sourcegraphPatternTypes são os tipos de busca aceitos em -pattern-type.
+var sourcegraphPatternTypes = []string{"literal", "regexp", "structural"}
+
+// sourcegraphMaxResults limita os arquivos de uma busca, como os 1000
+// resultados da API do GitHub; count: na query muda o limite.
+const sourcegraphMaxResults = 1000
+
+// sourcegraphSearchQuery pede os arquivos que casaram, com as linhas
+// encontradas. Os outros tipos de resultado (repositórios, commits) são
+// ignorados.
+const sourcegraphSearchQuery = `query ($query: String!, $patternType: SearchPatternType!) {
+  search(query: $query, version: V3, patternType: $patternType) {
+    results {
+      matchCount
+      results {
+        __typename
+        ... on FileMatch {
+          repository { name }
+          file { path url }
+          lineMatches { preview }
+        }
+      }
+    }
+  }
+}`
+
+// sourcegraphSearcher usa a API GraphQL de busca do Sourcegraph (-provider
+// sourcegraph), que faz a busca por regex ou estrutural (Comby) no servidor
+// (-pattern-type). Com -org, a busca fica restrita aos repositórios da
+// organização. O token de SRC_ACCESS_TOKEN é opcional no sourcegraph.com.
+type sourcegraphSearcher struct {
+	baseURL     string
+	token       string
+	org         string
+	patternType string
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+}
+
+func newSourcegraphSearcher(baseURL, token, org, patternType string, client *githubClient) *sourcegraphSearcher {
+	return &sourcegraphSearcher{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		token:       token,
+		org:         org,
+		patternType: patternType,
+		client:      client,
+	}
+}
+
+// sourcegraphSearchResponse é a parte usada da resposta da busca.
+type sourcegraphSearchResponse struct {
+	Search struct {
+		Results struct {
+			MatchCount int `json:"matchCount"`
+			Results    []struct {
+				Typename   string `json:"__typename"`
+				Repository struct {
+					Name string `json:"name"`
+				} `json:"repository"`
+				File struct {
+					Path string `json:"path"`
+					URL  string `json:"url"`
+				} `json:"file"`
+				LineMatches []struct {
+					Preview string `json:"preview"`
+				} `json:"lineMatches"`
+			} `json:"results"`
+		} `json:"results"`
+	} `json:"search"`
+}
+
+// searchCode faz a busca inteira na primeira página: a API do Sourcegraph não
+// pagina, só limita a quantidade de resultados.
+func (s *sourcegraphSearcher) searchCode(query string, page, _ int) (*CodeSearchResult, error) {
+	if page > 1 {
+		return &CodeSearchResult{last: true}, nil
+	}
+	full := query
+	if s.org != "" {
+		// Os nomes dos repositórios incluem o host (github.com/acme/app).
+		full += " repo:/" + regexp.QuoteMeta(s.org) + "/"
+	}
+	if !strings.Contains(full, "count:") {
+		full += fmt.Sprintf(" count:%d", sourcegraphMaxResults)
+	}
+	header := http.Header{}
+	if s.token != "" {
+		header.Set("Authorization", "token "+s.token)
+	}
+	var resp sourcegraphSearchResponse
+	variables := map[string]any{"query": full, "patternType": s.patternType}
+	errs, err := s.client.graphQL(s.baseURL+"/.api/graphql", header, sourcegraphSearchQuery, variables, &resp)
+	if err != nil {
+		return nil, err
+	}
+	// Com parte dos resultados (ex: tempo esgotado em alguns repositórios), a
+	// busca segue com o que veio.
+	for _, e := range errs {
+		log.Printf("Aviso: busca parcial no Sourcegraph: %s", e.Message)
+	}
+
+	// Converte para o formato da API do GitHub, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{last: true}
+	for _, r := range resp.Search.Results.Results {
+		if r.Typename != "FileMatch" {
+			continue
+		}
+		item := CodeSearchItem{
+			Path:    r.File.Path,
+			HTMLURL: s.baseURL + r.File.URL,
+		}
+		item.Repository.FullName = r.Repository.Name
+		lines := make([]string, len(r.LineMatches))
+		for i, l := range r.LineMatches {
+			lines[i] = l.Preview
+		}
+		item.TextMatches = []TextMatch{{Fragment: strings.Join(lines, "\n")}}
+		result.Items = append(result.Items, item)
+	}
+	result.TotalCount = len(result.Items)
+
+	if err := s.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
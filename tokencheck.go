@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// expiryWarning é a antecedência com que um token prestes a expirar é apontado.
+const expiryWarning = 7 * 24 * time.Hour
+
+// rateLimitResponse é a parte usada da resposta de /rate_limit.
+type rateLimitResponse struct {
+	Resources map[string]struct {
+		Limit     int   `json:"limit"`
+		Remaining int   `json:"remaining"`
+		Reset     int64 `json:"reset"`
+	} `json:"resources"`
+}
+
+// maskToken mostra só o começo e o fim do token, para o relatório não vazá-lo.
+func maskToken(t string) string {
+	if len(t) <= 12 {
+		return strings.Repeat("*", len(t))
+	}
+	return t[:4] + "…" + t[len(t)-4:]
+}
+
+// checkToken consulta /user e /rate_limit com um token e escreve o relatório:
+// usuário, cota da busca de código, escopos e expiração, com avisos para o que
+// impede ou limita a busca. Devolve false se o token não puder ser usado.
+func checkToken(c *githubClient, w io.Writer) (bool, error) {
+	status, header, body, err := c.get(c.api("/user"), "application/vnd.github+json")
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusUnauthorized {
+		fmt.Fprintln(w, "  erro: token inválido, expirado ou revogado")
+		return false, nil
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("API retornou status %d em /user: %s", status, string(body))
+	}
+	var user struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return false, fmt.Errorf("decodificar JSON: %w", err)
+	}
+	fmt.Fprintf(w, "  usuário: %s\n", user.Login)
+
+	var warnings []string
+	// Tokens clássicos informam os escopos; fine-grained e de App, não.
+	if scopes, ok := header["X-Oauth-Scopes"]; ok {
+		list := strings.Join(scopes, ",")
+		fmt.Fprintf(w, "  escopos: %s\n", list)
+		if !contains(strings.Split(strings.ReplaceAll(list, " ", ""), ","), "repo") {
+			warnings = append(warnings, "sem o escopo repo: só código de repositórios públicos aparece na busca")
+		}
+	} else {
+		fmt.Fprintln(w, "  escopos: não informados (token fine-grained ou de GitHub App)")
+	}
+	if exp := header.Get("GitHub-Authentication-Token-Expiration"); exp != "" {
+		fmt.Fprintf(w, "  expira em: %s\n", exp)
+		if t, err := time.Parse("2006-01-02 15:04:05 MST", exp); err == nil && time.Until(t) < expiryWarning {
+			warnings = append(warnings, "o token expira em menos de 7 dias")
+		}
+	} else {
+		fmt.Fprintln(w, "  expira em: sem data de expiração")
+	}
+
+	status, _, body, err = c.get(c.api("/rate_limit"), "application/vnd.github+json")
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case status == http.StatusNotFound && c.apiURL != "":
+		// O GitHub Enterprise Server com o limite desativado responde 404 aqui
+		// (e não manda os cabeçalhos X-RateLimit nas buscas).
+		fmt.Fprintln(w, "  busca de código: sem limite (desativado no servidor)")
+	case status != http.StatusOK:
+		return false, fmt.Errorf("API retornou status %d em /rate_limit: %s", status, string(body))
+	default:
+		var limits rateLimitResponse
+		if err := json.Unmarshal(body, &limits); err != nil {
+			return false, fmt.Errorf("decodificar JSON: %w", err)
+		}
+		// A busca de código tem um limite próprio; contas antigas e o
+		// Enterprise Server só trazem "search".
+		quota, ok := limits.Resources["code_search"]
+		if !ok {
+			quota = limits.Resources["search"]
+		}
+		reset := time.Unix(quota.Reset, 0)
+		fmt.Fprintf(w, "  busca de código: %d de %d restantes (renova às %s)\n", quota.Remaining, quota.Limit, reset.Format("15:04:05"))
+		if quota.Remaining == 0 {
+			warnings = append(warnings, "a cota da busca de código está esgotada")
+		}
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "  aviso: %s\n", warning)
+	}
+	return true, nil
+}
+
+// checkTokens roda checkToken para cada token do pool. Devolve false se algum
+// token não puder ser usado.
+func checkTokens(c *githubClient, tokens []string, w io.Writer) (bool, error) {
+	if len(tokens) == 0 {
+		fmt.Fprintln(w, "Nenhum token configurado: a busca de código da API exige autenticação (GITHUB_KEY, -tokens ou -client).")
+		return false, nil
+	}
+	ok := true
+	for i, t := range tokens {
+		fmt.Fprintf(w, "Token %d (%s):\n", i+1, maskToken(t))
+		single := *c
+		single.tokens = newTokenPool([]string{t})
+		valid, err := checkToken(&single, w)
+		if err != nil {
+			return false, err
+		}
+		ok = ok && valid
+	}
+	return ok, nil
+}
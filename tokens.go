@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// loadTokens lê a lista de -tokens: um arquivo com um token por linha ou, se
+// o arquivo não existir, tokens separados por vírgula.
+func loadTokens(spec string) ([]string, error) {
+	var tokens []string
+	file, err := os.Open(spec)
+	if os.IsNotExist(err) {
+		for _, t := range strings.Split(spec, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	return tokens, scanner.Err()
+}
+
+// resolveTokens escolhe os tokens do GitHub: os de -tokens, o do cliente
+// (-client), o da variável GITHUB_KEY, o guardado pelo login ou, com useGH, o
+// do gh CLI, nessa ordem. O token do login e o do gh são os do servidor do
+// cliente (github.com ou o Enterprise Server de -github-url).
+func resolveTokens(list string, tenant *clientConfig, useGH bool, c *githubClient) ([]string, error) {
+	switch {
+	case list != "":
+		tokens, err := loadTokens(list)
+		if err == nil && len(tokens) == 0 {
+			err = errors.New("nenhum token informado")
+		}
+		return tokens, err
+	case tenant != nil && tenant.token() != "":
+		return []string{tenant.token()}, nil
+	case os.Getenv("GITHUB_KEY") != "":
+		return []string{os.Getenv("GITHUB_KEY")}, nil
+	case loadCredential(apiHost(c.api(""))) != "":
+		return []string{loadCredential(apiHost(c.api("")))}, nil
+	}
+	if useGH {
+		if t := ghToken(c.host()); t != "" {
+			return []string{t}, nil
+		}
+	}
+	return nil, nil
+}
+
+// pooledToken é um token do pool com o último estado de limite informado pela API.
+type pooledToken struct {
+	value string
+	// remaining é -1 enquanto a API não informou o limite.
+	remaining int
+	limit     int
+	reset     time.Time
+	requests  int
+
+	// refresh, quando definido, emite um novo valor para o token, que expira
+	// em expires (tokens de instalação de GitHub App).
+	refresh func() (string, time.Time, error)
+	expires time.Time
+}
+
+// fresh renova o token se ele tiver validade e estiver perto de expirar.
+func (t *pooledToken) fresh() error {
+	if t.refresh == nil || (t.value != "" && time.Until(t.expires) > tokenRefreshMargin) {
+		return nil
+	}
+	value, expires, err := t.refresh()
+	if err != nil {
+		return err
+	}
+	t.value, t.expires = value, expires
+	return nil
+}
+
+// exhausted indica se o token esgotou o limite e a janela ainda não reiniciou.
+func (t *pooledToken) exhausted(now time.Time) bool {
+	return t.remaining == 0 && now.Before(t.reset)
+}
+
+// tokenPool reveza os tokens a cada requisição, pulando os que esgotaram o
+// limite, para espalhar o consumo de buscas longas entre várias contas.
+type tokenPool struct {
+	tokens []*pooledToken
+	next   int
+}
+
+// newTokenPool cria o pool; sem tokens, ele tem uma única entrada anônima
+// (value vazio), para os limites das requisições sem autenticação serem
+// acompanhados da mesma forma.
+func newTokenPool(values []string) *tokenPool {
+	p := &tokenPool{}
+	for _, v := range values {
+		p.tokens = append(p.tokens, &pooledToken{value: v, remaining: -1})
+	}
+	if len(p.tokens) == 0 {
+		p.tokens = []*pooledToken{{remaining: -1}}
+	}
+	return p
+}
+
+// newRefreshingPool cria um pool com um único token renovado por refresh.
+func newRefreshingPool(refresh func() (string, time.Time, error)) *tokenPool {
+	return &tokenPool{tokens: []*pooledToken{{remaining: -1, refresh: refresh}}}
+}
+
+// pick devolve o próximo token disponível. Se todos estiverem esgotados, fica
+// com o que reinicia primeiro.
+func (p *tokenPool) pick() *pooledToken {
+	now := time.Now()
+	var soonest *pooledToken
+	for range p.tokens {
+		t := p.tokens[p.next]
+		p.next = (p.next + 1) % len(p.tokens)
+		if !t.exhausted(now) {
+			return t
+		}
+		if soonest == nil || t.reset.Before(soonest.reset) {
+			soonest = t
+		}
+	}
+	return soonest
+}
+
+// searchResources são os valores de X-RateLimit-Resource da busca de código:
+// code_search no github.com e search no Enterprise Server e em contas antigas.
+var searchResources = []string{"code_search", "search"}
+
+// update registra o limite restante informado nos cabeçalhos da resposta.
+// Só o limite da busca é acompanhado; o das demais rotas (core) é separado.
+func (p *tokenPool) update(t *pooledToken, resp *http.Response) {
+	t.requests++
+	if resp == nil {
+		return
+	}
+	if r := resp.Header.Get("X-RateLimit-Resource"); r != "" && !contains(searchResources, r) {
+		return
+	}
+	if n, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		t.remaining = n
+	}
+	if n, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		t.limit = n
+	}
+	if n, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		t.reset = time.Unix(n, 0)
+	}
+}
+
+// throttle calcula a espera antes da próxima busca para usar o limite sem
+// estourá-lo: cada token disponível pode fazer remaining requisições até o
+// reset, e o revezamento divide a espera entre os tokens. Tokens esgotados
+// ficam de fora (veja exhaustedFor).
+func (p *tokenPool) throttle() time.Duration {
+	now := time.Now()
+	var wait time.Duration
+	available := 0
+	for _, t := range p.tokens {
+		if t.exhausted(now) {
+			continue
+		}
+		available++
+		// Sem informação do limite ainda, não há por que esperar.
+		if t.remaining <= 0 || !t.reset.After(now) {
+			continue
+		}
+		if d := t.reset.Sub(now) / time.Duration(t.remaining); d > wait {
+			wait = d
+		}
+	}
+	if available == 0 {
+		return 0
+	}
+	return wait / time.Duration(available)
+}
+
+// exhaustedFor devolve quanto falta para o primeiro reset quando todos os
+// tokens esgotaram o limite, ou zero se algum ainda estiver disponível.
+func (p *tokenPool) exhaustedFor() time.Duration {
+	now := time.Now()
+	var soonest time.Time
+	for _, t := range p.tokens {
+		if !t.exhausted(now) {
+			return 0
+		}
+		if soonest.IsZero() || t.reset.Before(soonest) {
+			soonest = t.reset
+		}
+	}
+	// Um segundo de folga para o relógio do servidor.
+	return soonest.Sub(now) + time.Second
+}
+
+// writeStats escreve o uso de cada token (-pool-stats): requisições feitas,
+// limite restante e quando ele renova, para saber quais estão esgotados.
+func (p *tokenPool) writeStats(w io.Writer) {
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOKEN\tREQUISIÇÕES\tRESTANTE\tRENOVA\tESTADO")
+	for _, t := range p.tokens {
+		name := maskToken(t.value)
+		switch {
+		case t.refresh != nil:
+			name = "(GitHub App)"
+		case t.value == "":
+			name = "(anônimo)"
+		}
+		remaining, reset := "?", "-"
+		if t.remaining >= 0 {
+			remaining = strconv.Itoa(t.remaining)
+			if t.limit > 0 {
+				remaining += "/" + strconv.Itoa(t.limit)
+			}
+		}
+		if t.reset.After(now) {
+			reset = t.reset.Format("15:04:05")
+		}
+		state := "ok"
+		if t.exhausted(now) {
+			state = "esgotado"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", name, t.requests, remaining, reset, state)
+	}
+	tw.Flush()
+}
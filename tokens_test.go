@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codeSearchResponse imita os cabeçalhos de limite que o github.com manda na
+// resposta de /search/code.
+func codeSearchResponse(status, remaining int, reset time.Time) *http.Response {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "10")
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	header.Set("X-RateLimit-Used", strconv.Itoa(10-remaining))
+	header.Set("X-RateLimit-Resource", "code_search")
+	return &http.Response{StatusCode: status, Header: header}
+}
+
+func TestTokenPoolUpdate(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	tests := []struct {
+		name      string
+		resource  string
+		remaining int
+		want      int
+	}{
+		{"code_search do github.com", "code_search", 7, 7},
+		{"search do Enterprise Server", "search", 7, 7},
+		{"sem o cabeçalho", "", 7, 7},
+		{"core fica de fora", "core", 4999, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTokenPool([]string{"t1"})
+			token := pool.pick()
+			resp := codeSearchResponse(http.StatusOK, tt.remaining, reset)
+			resp.Header.Set("X-RateLimit-Resource", tt.resource)
+			pool.update(token, resp)
+			if token.remaining != tt.want {
+				t.Fatalf("remaining = %d; quer %d", token.remaining, tt.want)
+			}
+			if tt.want >= 0 && (token.limit != 10 || !token.reset.Equal(reset)) {
+				t.Errorf("limit = %d, reset = %s; quer 10, %s", token.limit, token.reset, reset)
+			}
+		})
+	}
+}
+
+func TestTokenPoolThrottle(t *testing.T) {
+	pool := newTokenPool([]string{"t1"})
+	pool.update(pool.pick(), codeSearchResponse(http.StatusOK, 5, time.Now().Add(time.Minute)))
+	// 5 buscas restantes em um minuto: cerca de 12s entre elas.
+	if wait := pool.throttle(); wait < 10*time.Second || wait > 12*time.Second {
+		t.Errorf("throttle = %s; quer ~12s", wait)
+	}
+}
+
+func TestTokenPoolWriteStats(t *testing.T) {
+	pool := newTokenPool([]string{"ghp_aaaaaaaaaaaaaaaa", "ghp_bbbbbbbbbbbbbbbb"})
+	reset := time.Now().Add(time.Minute)
+	pool.update(pool.pick(), codeSearchResponse(http.StatusOK, 7, reset))
+	pool.update(pool.pick(), codeSearchResponse(http.StatusForbidden, 0, reset))
+	var b strings.Builder
+	pool.writeStats(&b)
+	out := b.String()
+	for _, want := range []string{"7/10", "0/10", reset.Format("15:04:05"), "esgotado"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("estatísticas sem %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "?") {
+		t.Errorf("estatísticas com limite desconhecido:\n%s", out)
+	}
+}
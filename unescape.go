@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Sequências de escape que escondem URLs em trechos de código: JSON
+// (https:\/\/host, /), strings C/JS (\x2F), URL encoding (https%3A%2F%2F)
+// e entidades HTML (&#x2F;, &#47;, &amp;).
+var percentDecoded = map[string]byte{
+	"2f": '/', "3a": ':', "3f": '?', "3d": '=', "26": '&', "23": '#', "40": '@',
+}
+
+var htmlEntities = map[string]byte{
+	"&amp;": '&', "&#x2f;": '/', "&#47;": '/', "&#x3a;": ':', "&#58;": ':', "&sol;": '/', "&colon;": ':',
+}
+
+// unescapeFragment desfaz as codificações comuns de URLs em um trecho antes da
+// extração. Devolve também, para cada byte do texto decodificado, a posição
+// correspondente no trecho original (com uma entrada extra para o fim), para
+// que as ocorrências continuem apontando para o texto como foi escrito.
+func unescapeFragment(s string) (string, []int) {
+	if !strings.ContainsAny(s, `\%&`) {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	offsets := make([]int, 0, len(s)+1)
+	emit := func(c byte, at int) {
+		b.WriteByte(c)
+		offsets = append(offsets, at)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '/':
+			emit('/', i)
+			i += 2
+			continue
+		case c == '\\' && i+5 < len(s) && s[i+1] == 'u':
+			if r, ok := decodeASCIIHex(s[i+2 : i+6]); ok {
+				emit(r, i)
+				i += 6
+				continue
+			}
+		case c == '\\' && i+3 < len(s) && s[i+1] == 'x':
+			if r, ok := decodeASCIIHex(s[i+2 : i+4]); ok {
+				emit(r, i)
+				i += 4
+				continue
+			}
+		case c == '%' && i+2 < len(s):
+			if r, ok := percentDecoded[strings.ToLower(s[i+1:i+3])]; ok {
+				emit(r, i)
+				i += 3
+				continue
+			}
+		case c == '&':
+			if end := strings.IndexByte(s[i:min(len(s), i+8)], ';'); end > 0 {
+				if r, ok := htmlEntities[strings.ToLower(s[i:i+end+1])]; ok {
+					emit(r, i)
+					i += end + 1
+					continue
+				}
+			}
+		}
+		emit(c, i)
+		i++
+	}
+	offsets = append(offsets, len(s))
+	return b.String(), offsets
+}
+
+// decodeASCIIHex decodifica um código hexadecimal de um caractere ASCII
+// imprimível; outros caracteres ficam como estão no trecho.
+func decodeASCIIHex(hex string) (byte, bool) {
+	n, err := strconv.ParseUint(hex, 16, 16)
+	if err != nil || n < 0x20 || n > 0x7e {
+		return 0, false
+	}
+	return byte(n), true
+}
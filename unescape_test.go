@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestUnescapeFragment(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{"sem escapes", "https://example.com/x", "https://example.com/x"},
+		{"JSON", `"https:\/\/example.com\/x"`, `"https://example.com/x"`},
+		{"\\u e \\x", `https:\u002F\u002Fa.io\x2Fb`, "https://a.io/b"},
+		{"URL encoding", "https%3A%2F%2Fa.io%2fb%3Fq%3D1", "https://a.io/b?q=1"},
+		{"entidades HTML", "https:&#x2F;&#47;a.io&sol;b?x=1&amp;y=2", "https://a.io/b?x=1&y=2"},
+		{"escape incompleto no fim", `a\u00`, `a\u00`},
+		{"porcentagem no fim", "50%", "50%"},
+		{"multibyte", `"ação:\/\/ȺȺ.io"`, `"ação://ȺȺ.io"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, offsets := unescapeFragment(tt.fragment)
+			if got != tt.want {
+				t.Fatalf("unescapeFragment(%q) = %q; quer %q", tt.fragment, got, tt.want)
+			}
+			if offsets == nil {
+				return
+			}
+			if len(offsets) != len(got)+1 || offsets[len(got)] != len(tt.fragment) {
+				t.Fatalf("posições %v para %q", offsets, got)
+			}
+			// Crescentes e, para bytes não decodificados, o mesmo byte do original.
+			for i := range got {
+				if i > 0 && offsets[i] <= offsets[i-1] {
+					t.Fatalf("posição %d fora de ordem: %v", i, offsets)
+				}
+				if at := offsets[i]; tt.fragment[at] != got[i] && !isEscapeStart(tt.fragment[at]) {
+					t.Errorf("byte %d (%q) aponta para %q", i, got[i], tt.fragment[at])
+				}
+			}
+		})
+	}
+}
+
+func isEscapeStart(c byte) bool {
+	return c == '\\' || c == '%' || c == '&'
+}
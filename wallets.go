@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Candidatos a endereços de carteira: Bitcoin legado (P2PKH/P2SH, Base58),
+// Bitcoin SegWit (bech32/bech32m) e Ethereum.
+var (
+	btcBase58Regex = regexp.MustCompile(`\b[13][1-9A-HJ-NP-Za-km-z]{25,34}\b`)
+	btcBech32Regex = regexp.MustCompile(`\b(?:bc1|BC1)[02-9ac-hj-np-zAC-HJ-NP-Z]{11,71}\b`)
+	ethRegex       = regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`)
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// validBase58Check confere o checksum de um endereço Base58Check: os 4
+// últimos bytes são o início do SHA-256 duplo do restante.
+func validBase58Check(addr string) bool {
+	n := new(big.Int)
+	for i := 0; i < len(addr); i++ {
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(strings.IndexByte(base58Alphabet, addr[i]))))
+	}
+	decoded := n.Bytes()
+	// Cada "1" no início representa um byte zero.
+	for i := 0; i < len(addr) && addr[i] == '1'; i++ {
+		decoded = append([]byte{0}, decoded...)
+	}
+	if len(decoded) != 25 {
+		return false
+	}
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	return bytes.Equal(second[:4], decoded[21:])
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// validBech32 confere o checksum de um endereço bech32 (SegWit v0) ou
+// bech32m (Taproot), conforme o BIP 173 e o BIP 350.
+func validBech32(addr string) bool {
+	if addr != strings.ToLower(addr) && addr != strings.ToUpper(addr) {
+		return false
+	}
+	addr = strings.ToLower(addr)
+	hrp, data, ok := strings.Cut(addr, "1")
+	if !ok || len(data) < 6 {
+		return false
+	}
+	values := make([]int, 0, len(hrp)*2+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]>>5))
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]&31))
+	}
+	for i := 0; i < len(data); i++ {
+		values = append(values, strings.IndexByte(bech32Charset, data[i]))
+	}
+	generator := []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ v
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	// 1 para bech32, 0x2bc830a3 para bech32m.
+	return chk == 1 || chk == 0x2bc830a3
+}
+
+// findWallets extrai endereços de carteiras Bitcoin e Ethereum. Os endereços
+// Bitcoin só valem com checksum correto, o que descarta hashes e
+// identificadores parecidos; os de Ethereum não têm checksum obrigatório e
+// valem pelo formato. A tag indica a moeda (btc ou eth).
+func findWallets(_ *extractor, fragment string) []extraction {
+	var found []extraction
+	for _, loc := range btcBase58Regex.FindAllStringIndex(fragment, -1) {
+		if validBase58Check(fragment[loc[0]:loc[1]]) {
+			found = append(found, extraction{Value: fragment[loc[0]:loc[1]], Start: loc[0], End: loc[1], Tags: []string{"btc"}})
+		}
+	}
+	for _, loc := range btcBech32Regex.FindAllStringIndex(fragment, -1) {
+		if addr := fragment[loc[0]:loc[1]]; validBech32(addr) {
+			found = append(found, extraction{Value: strings.ToLower(addr), Start: loc[0], End: loc[1], Tags: []string{"btc"}})
+		}
+	}
+	for _, loc := range ethRegex.FindAllStringIndex(fragment, -1) {
+		found = append(found, extraction{Value: fragment[loc[0]:loc[1]], Start: loc[0], End: loc[1], Tags: []string{"eth"}})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// githubWebURL é a raiz do site do GitHub, onde fica a busca de código nova.
+const githubWebURL = "https://github.com"
+
+// legacyQualifiers traduz qualificadores da busca antiga da API para a sintaxe
+// nova (-q2), que não os aceita.
+var legacyQualifiers = regexp.MustCompile(`(^|\s)(filename|extension):(\S+)`)
+
+// translateQuery converte filename:x e extension:y em path:**/x e path:*.y.
+func translateQuery(q string) string {
+	return legacyQualifiers.ReplaceAllStringFunc(q, func(m string) string {
+		sub := legacyQualifiers.FindStringSubmatch(m)
+		if sub[2] == "filename" {
+			return sub[1] + "path:**/" + sub[3]
+		}
+		return sub[1] + "path:*." + strings.TrimPrefix(sub[3], ".")
+	})
+}
+
+// webSearcher usa a busca de código nova do GitHub (-q2), que aceita /regex/ e
+// qualificadores como path: e content:, mas só existe no site e exige uma
+// sessão autenticada (cookie user_session em GITHUB_SESSION).
+type webSearcher struct {
+	session string
+	// client fornece o log de auditoria, as novas tentativas e a gravação (-record).
+	client *githubClient
+}
+
+// webSearchResponse é a parte usada do JSON devolvido pela página de busca.
+type webSearchResponse struct {
+	Payload struct {
+		ResultCount int `json:"result_count"`
+		PageCount   int `json:"page_count"`
+		Results     []struct {
+			RepoNWO   string `json:"repo_nwo"`
+			Path      string `json:"path"`
+			RefName   string `json:"ref_name"`
+			CommitSHA string `json:"commit_sha"`
+			Snippets  []struct {
+				Lines []string `json:"lines"`
+			} `json:"snippets"`
+		} `json:"results"`
+	} `json:"payload"`
+}
+
+// markupTags remove o destaque em HTML (<mark>) das linhas dos trechos.
+var markupTags = regexp.MustCompile(`<[^>]*>`)
+
+func (w *webSearcher) searchCode(query string, page, _ int) (*CodeSearchResult, error) {
+	apiURL := fmt.Sprintf("%s/search?q=%s&type=code&p=%d", githubWebURL, url.QueryEscape(translateQuery(query)), page)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("criar requisição: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.AddCookie(&http.Cookie{Name: "user_session", Value: w.session})
+
+	// Mesmas novas tentativas da API: falhas transitórias e limite secundário.
+	status, _, body, err := w.client.retry(func() (int, http.Header, []byte, error) {
+		return w.client.fetch(req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("busca retornou status %d: %s", status, string(body))
+	}
+	var web webSearchResponse
+	if err := json.Unmarshal(body, &web); err != nil {
+		return nil, fmt.Errorf("decodificar JSON (a sessão em GITHUB_SESSION pode ter expirado): %w", err)
+	}
+
+	// Converte para o formato da API, para o resto do pipeline não mudar.
+	result := &CodeSearchResult{TotalCount: web.Payload.ResultCount}
+	for _, r := range web.Payload.Results {
+		ref := r.CommitSHA
+		if ref == "" {
+			ref = strings.TrimPrefix(r.RefName, "refs/heads/")
+		}
+		item := CodeSearchItem{
+			Path:    r.Path,
+			HTMLURL: fmt.Sprintf("%s/%s/blob/%s/%s", githubWebURL, r.RepoNWO, ref, r.Path),
+		}
+		item.Repository.FullName = r.RepoNWO
+		for _, sn := range r.Snippets {
+			lines := make([]string, len(sn.Lines))
+			for i, l := range sn.Lines {
+				lines[i] = html.UnescapeString(markupTags.ReplaceAllString(l, ""))
+			}
+			item.TextMatches = append(item.TextMatches, TextMatch{Fragment: strings.Join(lines, "\n")})
+		}
+		result.Items = append(result.Items, item)
+	}
+	result.last = page >= web.Payload.PageCount
+
+	if err := w.client.record(query, page, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}